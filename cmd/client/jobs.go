@@ -0,0 +1,681 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/seanhagen/workernator/library/client"
+)
+
+// timeFormat is how times are shown to the user.
+const timeFormat = "2006-01-02 15:04:05"
+
+// stampFormat is how the time output was written is shown.
+const stampFormat = timeFormat + ".000"
+
+// newJobsCmd builds the 'jobs' command, which holds all the commands
+// for managing jobs.
+func newJobsCmd(flags *connFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Start, stop, list, watch, get the status of, tail the output of, and attach to jobs",
+	}
+
+	cmd.AddCommand(
+		newStartCmd(flags),
+		newStopCmd(flags),
+		newStatusCmd(flags),
+		newListCmd(flags),
+		newWatchCmd(flags),
+		newTailCmd(flags),
+		newAttachCmd(flags),
+	)
+	return cmd
+}
+
+func newStartCmd(flags *connFlags) *cobra.Command {
+	var (
+		maxRuntime time.Duration
+		stdin      string
+		tty        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "start <command> [args...]",
+		Short: "Start a job in the server",
+		Long: `Start a job in the server.
+
+With --stdin the contents of a file are sent to the stdin of the job, or
+with just --stdin everything read from this command's stdin is. The
+stdin of the job is closed once everything has been sent.
+
+With --tty the job is run with a terminal, which 'workernator jobs attach'
+can connect to for using the job interactively.`,
+		Example: `  workernator jobs start echo hello world
+  workernator jobs start -- ls -la /
+  workernator jobs start --max-runtime 5m -- make test
+  workernator jobs start --stdin=data.csv -- sort
+  echo hello | workernator jobs start --stdin cat
+  workernator jobs start --tty bash`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			var input io.ReadCloser
+			if stdin != "" {
+				var err error
+				if input, err = openInput(stdin, cmd.InOrStdin()); err != nil {
+					return err
+				}
+				defer func() { _ = input.Close() }()
+			}
+
+			fmt.Fprintln(out, "Contacting server...")
+			c, err := flags.connect()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			var opts []client.StartOption
+			if maxRuntime > 0 {
+				opts = append(opts, client.WithMaxRuntime(maxRuntime))
+			}
+			if input != nil {
+				opts = append(opts, client.WithStdin())
+			}
+			if tty {
+				opts = append(opts, client.WithTTY())
+			}
+
+			fmt.Fprintln(out, "Starting job...")
+			job, err := c.Start(cmd.Context(), args[0], args[1:], opts...)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "\nJob started, ID is '%v'\n\n", job.ID)
+			if tty {
+				fmt.Fprintf(out, "Use 'workernator jobs attach %v' to connect to its terminal.\n\n", job.ID)
+			}
+			if input == nil {
+				return nil
+			}
+
+			fmt.Fprintln(out, "Sending input...")
+			n, err := c.SendInput(cmd.Context(), job.ID, input)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Done, sent %v of input.\n\n", formatBytes(n))
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVarP(&maxRuntime, "max-runtime", "t", 0, "stop the job once it has run for this long, instead of the maximum configured on the server")
+	cmd.Flags().StringVar(&stdin, "stdin", "", "send the contents of a file to the stdin of the job, or this command's stdin if no file is given")
+	cmd.Flags().Lookup("stdin").NoOptDefVal = "-"
+	cmd.Flags().BoolVar(&tty, "tty", false, "run the job with a terminal, so it can be attached to")
+	// everything after the command belongs to the job, not to us
+	cmd.Flags().SetInterspersed(false)
+	return cmd
+}
+
+// openInput opens the named file to send to the stdin of a job, with '-'
+// meaning stdin.
+func openInput(name string, stdin io.Reader) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(stdin), nil
+	}
+	f, err := os.Open(name) //nolint:gosec // the user picks which file to send
+	if err != nil {
+		return nil, fmt.Errorf("unable to open input: %w", err)
+	}
+	return f, nil
+}
+
+func newStopCmd(flags *connFlags) *cobra.Command {
+	var gracePeriod time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "stop <id>",
+		Short: "Stop a running job",
+		Long: `Stop a running job. By default the job is killed immediately; use
+--grace-period to send it SIGTERM first, so it has a chance to clean up
+before being killed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			fmt.Fprintln(out, "Contacting server...")
+			c, err := flags.connect()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			var opts []client.StopOption
+			if gracePeriod > 0 {
+				opts = append(opts, client.WithGracePeriod(gracePeriod))
+			}
+
+			fmt.Fprintf(out, "Stopping job %v...\n", args[0])
+			if _, err := c.Stop(cmd.Context(), args[0], opts...); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "\nDone, job stopped.\n\n")
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVarP(&gracePeriod, "grace-period", "g", 0, "how long to wait for the job to exit after sending SIGTERM, before killing it")
+	return cmd
+}
+
+func newStatusCmd(flags *connFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status <id>",
+		Short: "Get the status of a job",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			fmt.Fprintln(out, "Contacting server...")
+			c, err := flags.connect()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			fmt.Fprintf(out, "Getting info for job %v...\n\n", args[0])
+			job, err := c.Status(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			printJob(out, job, time.Now())
+			return nil
+		},
+	}
+}
+
+func newListCmd(flags *connFlags) *cobra.Command {
+	var (
+		statuses      []string
+		owner         string
+		command       string
+		startedAfter  string
+		startedBefore string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List jobs",
+		Long: `List jobs, in the order they were started. Only jobs matching every
+filter are shown. Times are in the form '` + timeFormat + `', in local time.`,
+		Example: `  workernator jobs list --status running
+  workernator jobs list --owner alice --started-after '2022-07-07 16:00:00'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			opts, err := listOptions(statuses, owner, command, startedAfter, startedBefore)
+			if err != nil {
+				return err
+			}
+
+			c, err := flags.connect()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			// every page is fetched, so the table can be lined up
+			var jobs []*client.Job
+			var token string
+			for {
+				page, next, err := c.List(cmd.Context(), append(opts, client.WithPageToken(token))...)
+				if err != nil {
+					return err
+				}
+				jobs = append(jobs, page...)
+				if next == "" {
+					break
+				}
+				token = next
+			}
+
+			printJobs(cmd.OutOrStdout(), jobs, time.Now())
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringSliceVar(&statuses, "status", nil, "only show jobs with one of these statuses, ie 'running,failed'")
+	f.StringVar(&owner, "owner", "", "only show jobs started by this user")
+	f.StringVar(&command, "command", "", "only show jobs running this command")
+	f.StringVar(&startedAfter, "started-after", "", "only show jobs started at or after this time")
+	f.StringVar(&startedBefore, "started-before", "", "only show jobs started before this time")
+	return cmd
+}
+
+// listOptions builds the options for listing jobs from the flags of the
+// list command.
+func listOptions(statuses []string, owner, command, startedAfter, startedBefore string) ([]client.ListOption, error) {
+	var opts []client.ListOption
+
+	if len(statuses) > 0 {
+		var want []client.JobStatus
+		for _, name := range statuses {
+			status, err := parseStatus(name)
+			if err != nil {
+				return nil, err
+			}
+			want = append(want, status)
+		}
+		opts = append(opts, client.WithStatuses(want...))
+	}
+	if owner != "" {
+		opts = append(opts, client.WithOwner(owner))
+	}
+	if command != "" {
+		opts = append(opts, client.WithCommand(command))
+	}
+	if startedAfter != "" {
+		t, err := time.ParseInLocation(timeFormat, startedAfter, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --started-after: %w", err)
+		}
+		opts = append(opts, client.WithStartedAfter(t))
+	}
+	if startedBefore != "" {
+		t, err := time.ParseInLocation(timeFormat, startedBefore, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --started-before: %w", err)
+		}
+		opts = append(opts, client.WithStartedBefore(t))
+	}
+
+	return opts, nil
+}
+
+// parseStatus finds the job status with the given name, ignoring case.
+func parseStatus(name string) (client.JobStatus, error) {
+	statuses := []client.JobStatus{
+		client.StatusRunning,
+		client.StatusFailed,
+		client.StatusFinished,
+		client.StatusStopped,
+		client.StatusTimedOut,
+	}
+	for _, status := range statuses {
+		if strings.EqualFold(name, status.String()) {
+			return status, nil
+		}
+	}
+	return client.StatusUnknown, fmt.Errorf("unknown job status '%v'", name)
+}
+
+func newWatchCmd(flags *connFlags) *cobra.Command {
+	var owner string
+
+	cmd := &cobra.Command{
+		Use:   "watch [ids...]",
+		Short: "Watch jobs start and end",
+		Long: `Watch jobs start and end, showing each event as it happens until
+interrupted. If any IDs are given only those jobs are watched.`,
+		Example: `  workernator jobs watch
+  workernator jobs watch --owner alice`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			c, err := flags.connect()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			opts := []client.WatchOption{
+				client.WithReady(func() { fmt.Fprintln(out, "Watching for job events...") }),
+			}
+			if len(args) > 0 {
+				opts = append(opts, client.WithWatchIDs(args...))
+			}
+			if owner != "" {
+				opts = append(opts, client.WithWatchOwner(owner))
+			}
+
+			err = c.Watch(cmd.Context(), func(ev client.Event) error {
+				printEvent(out, ev)
+				return nil
+			}, opts...)
+			if errors.Is(err, context.Canceled) {
+				// interrupting is the only way to stop watching
+				return nil
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&owner, "owner", "", "only watch jobs started by this user")
+	return cmd
+}
+
+func newTailCmd(flags *connFlags) *cobra.Command {
+	var (
+		offset     int64
+		lines      int
+		follow     bool
+		stream     string
+		timestamps bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tail <id>",
+		Short: "View the output of a job",
+		Long: `View the output of a job, from the beginning. If the job is still
+running, new output is shown as it's produced until the job ends.
+
+By default what the job writes to stdout and stderr are shown on stdout
+and stderr respectively; use --stream to only show one of them on stdout.
+
+With --timestamps each line is shown with the time the job wrote it.
+
+If the output stops early, the offset it stopped at is shown so that
+--offset can be used to carry on from the same place.`,
+		Example: `  workernator jobs tail XE38YM
+  workernator jobs tail -n 10 XE38YM
+  workernator jobs tail --follow=false XE38YM
+  workernator jobs tail --stream stdout XE38YM | jq .
+  workernator jobs tail --timestamps XE38YM`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+
+			only, err := parseStream(stream)
+			if err != nil {
+				return err
+			}
+
+			c, err := flags.connect()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			opts := []client.TailOption{client.WithOffset(offset), client.WithFollow(follow)}
+			if cmd.Flags().Changed("lines") {
+				opts = append(opts, client.WithLastLines(lines))
+			}
+			errOut := cmd.ErrOrStderr()
+			if only != 0 {
+				opts = append(opts, client.WithStream(only))
+				errOut = out
+			}
+
+			var end int64
+			if timestamps {
+				end, err = tailWithTimestamps(cmd.Context(), c, args[0], out, errOut, opts)
+			} else {
+				end, err = c.TailTo(cmd.Context(), out, args[0], append(opts, client.WithStderrTo(errOut))...)
+			}
+			if err != nil {
+				if end > offset {
+					fmt.Fprintf(cmd.ErrOrStderr(), "\nOutput stopped at offset %v, use '--offset %v' to carry on from there.\n", end, end)
+				}
+				return err
+			}
+
+			if follow {
+				// this goes to stderr so it doesn't end up mixed in with
+				// the output of the job
+				fmt.Fprintf(cmd.ErrOrStderr(), "\nJob finished, no more output, exiting tail!\n\n")
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.Int64Var(&offset, "offset", 0, "byte offset in the output to start from")
+	f.IntVarP(&lines, "lines", "n", 0, "start from the last N lines of output so far, 0 only shows new output")
+	f.BoolVarP(&follow, "follow", "f", true, "keep showing new output until the job ends")
+	f.StringVar(&stream, "stream", "both", "which output to show: stdout, stderr, or both")
+	f.BoolVar(&timestamps, "timestamps", false, "show the time each line was written")
+	return cmd
+}
+
+func newAttachCmd(flags *connFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attach <id>",
+		Short: "Connect to the terminal of a job",
+		Long: `Connect to the terminal of a job started with --tty. Everything typed is
+sent to the job, and everything the job writes to its terminal from now
+on is shown, until the job ends.
+
+While attached the local terminal is put into raw mode, so keys such as
+Ctrl-C are sent to the job instead of stopping this command. Press Ctrl-]
+to detach, which leaves the job running.`,
+		Example: `  workernator jobs start --tty bash
+  workernator jobs attach XE38YM`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			id := args[0]
+
+			c, err := flags.connect()
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			ctx, cancel := context.WithCancel(cmd.Context())
+			defer cancel()
+
+			fmt.Fprintf(out, "Attaching to job '%v', press Ctrl-] to detach.\n\n", id)
+
+			var opts []client.AttachOption
+			restore := func() {}
+			if f, ok := cmd.InOrStdin().(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+				var sizes <-chan client.WindowSize
+				if restore, sizes, err = rawTerminal(ctx, f); err != nil {
+					return err
+				}
+				opts = append(opts, client.WithWindowSizes(sizes))
+			}
+
+			err = c.Attach(ctx, id, &detachReader{r: cmd.InOrStdin()}, out, opts...)
+			restore()
+			if err != nil {
+				return err
+			}
+
+			job, err := c.Status(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+			if job.Status == client.StatusRunning {
+				fmt.Fprintf(out, "\nDetached from job '%v', which is still running.\n\n", id)
+			} else {
+				fmt.Fprintf(out, "\nJob '%v' has ended with status %v.\n\n", id, job.Status)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// parseStream finds the output stream with the given name, ignoring
+// case. Zero is returned for 'both'.
+func parseStream(name string) (client.Stream, error) {
+	if strings.EqualFold(name, "both") {
+		return 0, nil
+	}
+	for _, stream := range []client.Stream{client.StreamStdout, client.StreamStderr} {
+		if strings.EqualFold(name, stream.String()) {
+			return stream, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown stream '%v', must be one of stdout, stderr, or both", name)
+}
+
+// tailWithTimestamps writes the output of a job like TailTo does, but
+// with the time the job wrote each line at the start of it.
+func tailWithTimestamps(ctx context.Context, c *client.Client, id string, stdout, stderr io.Writer, opts []client.TailOption) (int64, error) {
+	out, errOut := &timestampWriter{w: stdout}, &timestampWriter{w: stderr}
+	return c.Tail(ctx, id, func(chunk client.Chunk) error {
+		w := out
+		if chunk.Stream == client.StreamStderr {
+			w = errOut
+		}
+		return w.write(chunk.At, chunk.Data)
+	}, append(opts, client.WithTimestamps(true))...)
+}
+
+// timestampWriter writes output with the time it was written at the
+// start of each line. A line written over several chunks gets the time
+// of the first one.
+type timestampWriter struct {
+	w io.Writer
+	// midLine is set if the last output written didn't end with a
+	// newline.
+	midLine bool
+}
+
+func (t *timestampWriter) write(at time.Time, data []byte) error {
+	stamp := at.Local().Format(stampFormat) + "  "
+
+	var buf bytes.Buffer
+	for len(data) > 0 {
+		if !t.midLine {
+			buf.WriteString(stamp)
+		}
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			buf.Write(data)
+			t.midLine = true
+			break
+		}
+		buf.Write(data[:i+1])
+		data = data[i+1:]
+		t.midLine = false
+	}
+
+	if _, err := t.w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("unable to write output: %w", err)
+	}
+	return nil
+}
+
+// printJob writes the human-readable status block for a job. now is
+// used to work out how long a job that's still running has been going.
+func printJob(w io.Writer, job *client.Job, now time.Time) {
+	fmt.Fprintln(w, "Job Status:")
+	fmt.Fprintf(w, "ID:         %v\n", job.ID)
+	if job.Owner != "" {
+		fmt.Fprintf(w, "Owner:      %v\n", job.Owner)
+	}
+	fmt.Fprintf(w, "Command:    %v\n", job.Command)
+	if len(job.Args) > 0 {
+		fmt.Fprintln(w, "Arguments:")
+		for _, arg := range job.Args {
+			fmt.Fprintf(w, "  - %v\n", arg)
+		}
+	}
+	fmt.Fprintf(w, "Status:     %v\n", job.Status)
+	if job.ErrorMsg != "" {
+		fmt.Fprintf(w, "Error:      %v\n", job.ErrorMsg)
+	}
+	if job.Signal != "" {
+		fmt.Fprintf(w, "Signal:     %v\n", job.Signal)
+	}
+	if job.MaxRuntime > 0 {
+		fmt.Fprintf(w, "Timeout:    %v\n", job.MaxRuntime)
+	}
+	if job.TTY {
+		fmt.Fprintln(w, "Terminal:   yes")
+	}
+	fmt.Fprintf(w, "Started:    %v\n", job.StartedAt.Local().Format(timeFormat))
+
+	if job.EndedAt.IsZero() {
+		fmt.Fprintln(w, "Finished:   -")
+		fmt.Fprintf(w, "Duration:   %v (still running)\n", now.Sub(job.StartedAt).Round(time.Second))
+	} else {
+		fmt.Fprintf(w, "Finished:   %v\n", job.EndedAt.Local().Format(timeFormat))
+		fmt.Fprintf(w, "Duration:   %v\n", job.EndedAt.Sub(job.StartedAt).Round(time.Millisecond))
+		fmt.Fprintf(w, "Reason:     %v\n", job.Reason)
+		fmt.Fprintf(w, "Exit Code:  %v\n", job.ExitCode)
+		fmt.Fprintln(w, "Usage:")
+		fmt.Fprintf(w, "  CPU:      %v user, %v system\n", job.Usage.UserCPU, job.Usage.SystemCPU)
+		fmt.Fprintf(w, "  Memory:   %v peak\n", formatBytes(job.Usage.PeakMemoryBytes))
+		fmt.Fprintf(w, "  IO:       %v read, %v written\n", formatBytes(job.Usage.IOReadBytes), formatBytes(job.Usage.IOWriteBytes))
+	}
+	fmt.Fprintln(w)
+}
+
+// printJobs writes a table of jobs, one per row. now is used to work out
+// how long jobs that are still running have been going.
+func printJobs(w io.Writer, jobs []*client.Job, now time.Time) {
+	if len(jobs) == 0 {
+		fmt.Fprintln(w, "No jobs found.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tOWNER\tSTATUS\tSTARTED\tDURATION\tCOMMAND")
+	for _, job := range jobs {
+		duration := now.Sub(job.StartedAt).Round(time.Second)
+		if !job.EndedAt.IsZero() {
+			duration = job.EndedAt.Sub(job.StartedAt).Round(time.Millisecond)
+		}
+
+		owner := job.Owner
+		if owner == "" {
+			owner = "-"
+		}
+
+		command := strings.Join(append([]string{job.Command}, job.Args...), " ")
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\n",
+			job.ID, owner, job.Status, job.StartedAt.Local().Format(timeFormat), duration, command)
+	}
+	_ = tw.Flush()
+}
+
+// printEvent writes a single line describing an event.
+func printEvent(w io.Writer, ev client.Event) {
+	owner := ev.Job.Owner
+	if owner == "" {
+		owner = "-"
+	}
+
+	command := strings.Join(append([]string{ev.Job.Command}, ev.Job.Args...), " ")
+	line := fmt.Sprintf("%v  %-8v  %v  %v  %v", ev.At.Local().Format(timeFormat), ev.Type, ev.Job.ID, owner, command)
+	if ev.Job.ErrorMsg != "" {
+		line += fmt.Sprintf(" (%v)", ev.Job.ErrorMsg)
+	}
+	fmt.Fprintln(w, line)
+}
+
+// formatBytes formats a number of bytes using the largest binary unit
+// that keeps the value at least one, ie '1.5 MiB'.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seanhagen/workernator/library/client"
+)
+
+func TestPrintJob(t *testing.T) {
+	started := time.Date(2022, time.July, 7, 16, 34, 3, 0, time.Local)
+	job := &client.Job{
+		ID:       "XE38YM",
+		Owner:    "alice",
+		Status:   client.StatusFinished,
+		Command:  "fib",
+		Args:     []string{"3"},
+		Reason:   client.ReasonExited,
+		ExitCode: 0,
+		Usage: client.ResourceUsage{
+			UserCPU:         250 * time.Millisecond,
+			SystemCPU:       10 * time.Millisecond,
+			PeakMemoryBytes: 3 * 1024 * 1024 / 2,
+			IOWriteBytes:    512,
+		},
+		StartedAt: started,
+		EndedAt:   started.Add(time.Second),
+	}
+
+	var buf bytes.Buffer
+	printJob(&buf, job, started.Add(time.Minute))
+	assert.Equal(t, `Job Status:
+ID:         XE38YM
+Owner:      alice
+Command:    fib
+Arguments:
+  - 3
+Status:     Finished
+Started:    2022-07-07 16:34:03
+Finished:   2022-07-07 16:34:04
+Duration:   1s
+Reason:     Exited
+Exit Code:  0
+Usage:
+  CPU:      250ms user, 10ms system
+  Memory:   1.5 MiB peak
+  IO:       0 B read, 512 B written
+
+`, buf.String())
+}
+
+func TestPrintJob_Running(t *testing.T) {
+	started := time.Date(2022, time.July, 7, 16, 34, 3, 0, time.Local)
+	job := &client.Job{
+		ID:         "XE38YM",
+		Status:     client.StatusRunning,
+		Command:    "sleep",
+		MaxRuntime: 5 * time.Minute,
+		TTY:        true,
+		StartedAt:  started,
+	}
+
+	var buf bytes.Buffer
+	printJob(&buf, job, started.Add(90*time.Second))
+	assert.Equal(t, `Job Status:
+ID:         XE38YM
+Command:    sleep
+Status:     Running
+Timeout:    5m0s
+Terminal:   yes
+Started:    2022-07-07 16:34:03
+Finished:   -
+Duration:   1m30s (still running)
+
+`, buf.String())
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:                      "0 B",
+		1023:                   "1023 B",
+		1024:                   "1.0 KiB",
+		10 * 1024 * 1024:       "10.0 MiB",
+		3 * 1024 * 1024 * 1024: "3.0 GiB",
+	}
+	for n, expect := range tests {
+		assert.Equal(t, expect, formatBytes(n))
+	}
+}
+
+func TestRequiredFlags(t *testing.T) {
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"jobs", "status", "XE38YM"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "required flag")
+}
+
+func TestPrintJobs(t *testing.T) {
+	started := time.Date(2022, time.July, 7, 16, 34, 3, 0, time.Local)
+	jobs := []*client.Job{
+		{
+			ID:        "XE38YM",
+			Owner:     "alice",
+			Status:    client.StatusFinished,
+			Command:   "fib",
+			Args:      []string{"3"},
+			StartedAt: started,
+			EndedAt:   started.Add(1500 * time.Millisecond),
+		},
+		{
+			ID:        "XE38YN",
+			Status:    client.StatusRunning,
+			Command:   "sleep",
+			Args:      []string{"300"},
+			StartedAt: started.Add(time.Minute),
+		},
+	}
+
+	var buf bytes.Buffer
+	printJobs(&buf, jobs, started.Add(2*time.Minute))
+	assert.Equal(t, `ID      OWNER  STATUS    STARTED              DURATION  COMMAND
+XE38YM  alice  Finished  2022-07-07 16:34:03  1.5s      fib 3
+XE38YN  -      Running   2022-07-07 16:35:03  1m0s      sleep 300
+`, buf.String())
+
+	buf.Reset()
+	printJobs(&buf, nil, started)
+	assert.Equal(t, "No jobs found.\n", buf.String())
+}
+
+func TestListOptions(t *testing.T) {
+	opts, err := listOptions([]string{"running", "TimedOut"}, "alice", "sleep", "2022-07-07 16:34:03", "")
+	require.NoError(t, err)
+	assert.Len(t, opts, 4)
+
+	_, err = listOptions([]string{"sleeping"}, "", "", "", "")
+	assert.Error(t, err)
+	_, err = listOptions(nil, "", "", "", "yesterday")
+	assert.Error(t, err)
+}
+
+func TestPrintEvent(t *testing.T) {
+	at := time.Date(2022, time.July, 7, 16, 34, 3, 0, time.Local)
+
+	var buf bytes.Buffer
+	printEvent(&buf, client.Event{
+		Type: client.EventStarted,
+		Job:  &client.Job{ID: "XE38YM", Owner: "alice", Command: "fib", Args: []string{"3"}},
+		At:   at,
+	})
+	printEvent(&buf, client.Event{
+		Type: client.EventFailed,
+		Job:  &client.Job{ID: "XE38YN", Command: "false", ErrorMsg: "exit status 1"},
+		At:   at,
+	})
+	assert.Equal(t, `2022-07-07 16:34:03  Started   XE38YM  alice  fib 3
+2022-07-07 16:34:03  Failed    XE38YN  -  false (exit status 1)
+`, buf.String())
+}
+
+func TestParseStream(t *testing.T) {
+	tests := map[string]client.Stream{
+		"both":   0,
+		"stdout": client.StreamStdout,
+		"STDERR": client.StreamStderr,
+	}
+	for name, expect := range tests {
+		got, err := parseStream(name)
+		require.NoError(t, err)
+		assert.Equal(t, expect, got)
+	}
+
+	_, err := parseStream("stdin")
+	assert.Error(t, err)
+}
+
+func TestTimestampWriter(t *testing.T) {
+	first := time.Date(2022, time.July, 7, 16, 34, 3, 120000000, time.Local)
+	second := first.Add(1500 * time.Millisecond)
+
+	var buf bytes.Buffer
+	w := &timestampWriter{w: &buf}
+	require.NoError(t, w.write(first, []byte("one\ntw")))
+	require.NoError(t, w.write(second, []byte("o\nthree\n")))
+	require.NoError(t, w.write(second, []byte("\x00\x01")))
+
+	expect := "2022-07-07 16:34:03.120  one\n" +
+		"2022-07-07 16:34:03.120  two\n" +
+		"2022-07-07 16:34:04.620  three\n" +
+		"2022-07-07 16:34:04.620  \x00\x01"
+	assert.Equal(t, expect, buf.String())
+}
+
+func TestOpenInput(t *testing.T) {
+	r, err := openInput("-", strings.NewReader("from stdin"))
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "from stdin", string(got))
+
+	path := filepath.Join(t.TempDir(), "input")
+	require.NoError(t, os.WriteFile(path, []byte("from a file"), 0o600))
+	r, err = openInput(path, strings.NewReader("from stdin"))
+	require.NoError(t, err)
+	got, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "from a file", string(got))
+	require.NoError(t, r.Close())
+
+	_, err = openInput(filepath.Join(t.TempDir(), "missing"), nil)
+	assert.Error(t, err)
+}
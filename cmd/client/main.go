@@ -0,0 +1,21 @@
+// Command client is the workernator CLI client, used to start, stop, get
+// the status of, and tail the output of jobs running on a workernator
+// server.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	// cancelling the context stops things like tailing output cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := newRootCmd().ExecuteContext(ctx)
+	stop()
+	if err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/seanhagen/workernator/library/client"
+)
+
+// connFlags holds the flags used to connect to the server.
+type connFlags struct {
+	server   string
+	rootCert string
+	cert     string
+	key      string
+}
+
+// newRootCmd builds the top-level 'workernator' command.
+func newRootCmd() *cobra.Command {
+	var flags connFlags
+
+	cmd := &cobra.Command{
+		Use:   "workernator",
+		Short: "CLI client for the workernator job runner",
+		Long: `Workernator is a job-runner library, server, and CLI client used for
+long-running tasks you don't want to run as part of your core service.
+
+This is the CLI client application, which allows you to start jobs,
+stop jobs, get the status of jobs, and tail the output of any job.`,
+		SilenceUsage: true,
+	}
+
+	pf := cmd.PersistentFlags()
+	pf.StringVarP(&flags.server, "server", "s", "", "address of the workernator server, ie 'localhost:8080'")
+	pf.StringVar(&flags.rootCert, "ca", "", "path to the CA certificate used to verify the server")
+	pf.StringVar(&flags.cert, "cert", "", "path to the client certificate")
+	pf.StringVar(&flags.key, "key", "", "path to the client certificate key")
+	for _, name := range []string{"server", "ca", "cert", "key"} {
+		_ = cmd.MarkPersistentFlagRequired(name)
+	}
+
+	cmd.AddCommand(newJobsCmd(&flags))
+	return cmd
+}
+
+// connect builds a client using the connection flags.
+func (f *connFlags) connect() (*client.Client, error) {
+	return client.New(f.server,
+		client.WithRootCert(f.rootCert),
+		client.WithCert(f.cert),
+		client.WithKey(f.key),
+	)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/term"
+
+	"github.com/seanhagen/workernator/library/client"
+)
+
+// detachKey is the key pressed to detach from a job, Ctrl-].
+const detachKey = 0x1d
+
+// detachReader reads the input for a job until the detach key is
+// pressed, after which it returns io.EOF.
+type detachReader struct {
+	r io.Reader
+	// detached is set once the detach key has been read.
+	detached bool
+}
+
+// Read implements io.Reader.
+func (d *detachReader) Read(p []byte) (int, error) {
+	if d.detached {
+		return 0, io.EOF
+	}
+	n, err := d.r.Read(p)
+	// anything typed before the detach key is still sent
+	if i := bytes.IndexByte(p[:n], detachKey); i >= 0 {
+		d.detached = true
+		return i, io.EOF
+	}
+	return n, err
+}
+
+// rawTerminal puts the terminal f into raw mode, so that every key
+// pressed is sent to the job as-is. It returns a function that restores
+// the terminal, and a channel that's sent the size of the terminal now
+// and whenever it changes, until ctx is cancelled.
+func rawTerminal(ctx context.Context, f *os.File) (func(), <-chan client.WindowSize, error) {
+	fd := int(f.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to put terminal into raw mode: %w", err)
+	}
+
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+
+	sizes := make(chan client.WindowSize)
+	go func() {
+		defer signal.Stop(winch)
+		for {
+			if cols, rows, err := term.GetSize(fd); err == nil {
+				select {
+				case sizes <- client.WindowSize{Rows: uint16(rows), Cols: uint16(cols)}:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-winch:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() { _ = term.Restore(fd, state) }, sizes, nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetachReader(t *testing.T) {
+	// input typed before the detach key is still sent, but nothing after
+	r := &detachReader{r: strings.NewReader("ls\r\x1dexit\r")}
+	got, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "ls\r", string(got))
+	assert.True(t, r.detached)
+
+	r = &detachReader{r: iotest.OneByteReader(strings.NewReader("ls\r"))}
+	got, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "ls\r", string(got))
+	assert.False(t, r.detached)
+}
@@ -0,0 +1,20 @@
+// Command server runs the workernator GRPC service, which lets
+// authenticated clients start, stop, get the status of, and tail the
+// output of jobs.
+package main
+
+import (
+	"os"
+
+	"github.com/seanhagen/workernator/library/api"
+)
+
+func main() {
+	// jobs are started by re-executing this binary, so this has to
+	// happen before anything else
+	api.Init()
+
+	if err := newRootCmd().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
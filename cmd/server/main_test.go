@@ -0,0 +1,23 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/seanhagen/workernator/internal/tlsconfig"
+	"github.com/seanhagen/workernator/library/api"
+)
+
+// the certificates in the GRPC package testdata have expired, so the
+// tests pretend it's still the time they were valid
+func testdataTime() time.Time {
+	return time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func TestMain(m *testing.M) {
+	// the server starts jobs by re-executing the test binary
+	api.Init()
+	tlsconfig.SetTime(testdataTime)
+	os.Exit(m.Run())
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	wgrpc "github.com/seanhagen/workernator/internal/grpc"
+	"github.com/seanhagen/workernator/internal/pb"
+	"github.com/seanhagen/workernator/internal/tlsconfig"
+	"github.com/seanhagen/workernator/library/api"
+)
+
+// shutdownTimeout is how long the server waits for requests to finish
+// after all the jobs have been stopped, before closing every connection.
+const shutdownTimeout = 10 * time.Second
+
+// defaultLimitCeilings are the most any job is allowed to ask for unless
+// the ceiling flags are used: 100 processes, one CPU, and 512M of memory.
+var defaultLimitCeilings = api.Limits{
+	MaxPids:     100,
+	CPUQuota:    time.Second,
+	CPUPeriod:   time.Second,
+	MemoryBytes: 512 * 1024 * 1024,
+}
+
+// serverFlags holds the flags used to configure the server.
+type serverFlags struct {
+	host     string
+	port     int
+	hostCert string
+	hostKey  string
+	rootCert string
+	debug    bool
+
+	workDir    string
+	rootFS     string
+	cgroup     string
+	maxRuntime time.Duration
+	jobUID     int
+	jobGID     int
+
+	defaultLimits api.Limits
+	limitCeilings api.Limits
+}
+
+// newRootCmd builds the command that runs the server.
+func newRootCmd() *cobra.Command {
+	var flags serverFlags
+
+	cmd := &cobra.Command{
+		Use:   "server",
+		Short: "Run the workernator GRPC service",
+		Long: `Runs the workernator GRPC service, which lets authenticated clients
+start, stop, get the status of, and tail the output of jobs.
+
+Clients must connect using a certificate signed by the root certificate.`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGTERM, syscall.SIGINT)
+			defer stop()
+			return run(ctx, flags)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&flags.host, "host", "localhost", "address to bind to")
+	f.IntVar(&flags.port, "port", 8080, "port to bind to")
+	f.StringVar(&flags.hostCert, "hostCert", "", "path to the TLS certificate for the service")
+	f.StringVar(&flags.hostKey, "hostKey", "", "path to the TLS key for the service")
+	f.StringVar(&flags.rootCert, "rootCert", "", "path to the CA root certificate used to sign the client certificates")
+	f.BoolVar(&flags.debug, "debug", false, "output debug messages")
+	f.StringVar(&flags.workDir, "workDir", "", "directory to store job data in, a temporary directory is used if not set")
+	f.StringVar(&flags.rootFS, "rootfs", "", "tarball to unpack as the root filesystem for each job")
+	f.StringVar(&flags.cgroup, "cgroup", "", "cgroup v2 directory to create job cgroups under, resource limits are only applied if set")
+	f.DurationVar(&flags.maxRuntime, "maxRuntime", 0, "longest any job can run, also used for jobs that don't set their own; jobs can run forever if not set")
+	f.IntVar(&flags.jobUID, "jobUID", 0, "host user ID root inside jobs maps to; defaults to the user running the server, or nobody (65534) if that's root")
+	f.IntVar(&flags.jobGID, "jobGID", 0, "host group ID root inside jobs maps to; defaults to the group running the server, or nobody (65534) if that's root")
+
+	defaults, ceilings := &flags.defaultLimits, &flags.limitCeilings
+	f.Int64Var(&defaults.MaxPids, "maxPids", api.DefaultLimits.MaxPids, "max number of processes for jobs that don't set their own, 0 for no limit if the matching ceiling is also 0")
+	f.DurationVar(&defaults.CPUQuota, "cpuQuota", api.DefaultLimits.CPUQuota, "CPU time per cpuPeriod for jobs that don't set their own, 0 for no limit if the matching ceiling is also 0")
+	f.DurationVar(&defaults.CPUPeriod, "cpuPeriod", api.DefaultLimits.CPUPeriod, "period cpuQuota applies to, 0 for the kernel default")
+	f.Int64Var(&defaults.MemoryBytes, "memory", api.DefaultLimits.MemoryBytes, "max bytes of memory for jobs that don't set their own, 0 for no limit if the matching ceiling is also 0")
+	f.Int64Var(&ceilings.MaxPids, "maxPidsCeiling", defaultLimitCeilings.MaxPids, "most processes any job can ask for, 0 for no ceiling")
+	f.DurationVar(&ceilings.CPUQuota, "cpuQuotaCeiling", defaultLimitCeilings.CPUQuota, "most CPU time per cpuPeriodCeiling any job can ask for, 0 for no ceiling")
+	f.DurationVar(&ceilings.CPUPeriod, "cpuPeriodCeiling", defaultLimitCeilings.CPUPeriod, "period cpuQuotaCeiling applies to, 0 for the kernel default")
+	f.Int64Var(&ceilings.MemoryBytes, "memoryCeiling", defaultLimitCeilings.MemoryBytes, "most bytes of memory any job can ask for, 0 for no ceiling")
+
+	for _, name := range []string{"hostCert", "hostKey", "rootCert"} {
+		_ = cmd.MarkFlagRequired(name)
+	}
+
+	return cmd
+}
+
+// run starts the server, and runs it until ctx is cancelled.
+func run(ctx context.Context, flags serverFlags) error {
+	log, err := newLogger(flags.debug)
+	if err != nil {
+		return fmt.Errorf("unable to set up logging: %w", err)
+	}
+	defer func() { _ = log.Sync() }()
+
+	creds, err := tlsconfig.ServerCredentials(tlsconfig.Config{
+		RootCert: flags.rootCert,
+		Cert:     flags.hostCert,
+		Key:      flags.hostKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	manager, err := api.NewManager(managerOptions(flags)...)
+	if err != nil {
+		return fmt.Errorf("unable to create job manager: %w", err)
+	}
+
+	addr := net.JoinHostPort(flags.host, strconv.Itoa(flags.port))
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("unable to listen on '%v': %w", addr, err)
+	}
+
+	srv := grpc.NewServer(
+		grpc.Creds(creds),
+		grpc.ChainUnaryInterceptor(
+			grpc_zap.UnaryServerInterceptor(log),
+			wgrpc.UnaryAuthInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			grpc_zap.StreamServerInterceptor(log),
+			wgrpc.StreamAuthInterceptor(),
+		),
+	)
+	pb.RegisterServiceServer(srv, wgrpc.NewServer(manager))
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Info("server started", zap.String("addr", lis.Addr().String()))
+		serveErr <- srv.Serve(lis)
+	}()
+
+	select {
+	case err := <-serveErr:
+		_ = manager.Shutdown()
+		return fmt.Errorf("server stopped unexpectedly: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Info("shutting down")
+	shutdown(log, srv, manager)
+	log.Info("server stopped")
+	return nil
+}
+
+// shutdown stops the server gracefully. No new requests are accepted,
+// and every running job is stopped so that any clients tailing output
+// get the rest of it before their stream ends.
+func shutdown(log *zap.Logger, srv *grpc.Server, manager *api.Manager) {
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	if err := manager.Shutdown(); err != nil {
+		log.Error("unable to stop all jobs", zap.Error(err))
+	}
+	log.Debug("all jobs stopped")
+
+	select {
+	case <-stopped:
+	case <-time.After(shutdownTimeout):
+		log.Warn("requests still running after jobs stopped, closing connections")
+		srv.Stop()
+	}
+}
+
+// managerOptions builds the options for the job manager from the flags.
+// The limits are always set, as a zero value means "no limit" rather
+// than "use the default".
+func managerOptions(flags serverFlags) []api.Option {
+	opts := []api.Option{
+		api.WithDefaultLimits(flags.defaultLimits),
+		api.WithLimitCeilings(flags.limitCeilings),
+	}
+	if flags.workDir != "" {
+		opts = append(opts, api.WithWorkDir(flags.workDir))
+	}
+	if flags.rootFS != "" {
+		opts = append(opts, api.WithRootFS(flags.rootFS))
+	}
+	if flags.cgroup != "" {
+		opts = append(opts, api.WithCgroup(flags.cgroup))
+	}
+	if flags.maxRuntime > 0 {
+		opts = append(opts, api.WithMaxRuntimeCeiling(flags.maxRuntime))
+	}
+	if flags.jobUID != 0 || flags.jobGID != 0 {
+		opts = append(opts, api.WithJobIDs(flags.jobUID, flags.jobGID))
+	}
+	return opts
+}
+
+// newLogger builds the logger for the server, which only outputs debug
+// messages if debug is true.
+func newLogger(debug bool) (*zap.Logger, error) {
+	conf := zap.NewProductionConfig()
+	if debug {
+		conf.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
+	}
+	return conf.Build()
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/seanhagen/workernator/library/api"
+	"github.com/seanhagen/workernator/library/client"
+)
+
+func TestRequiredFlags(t *testing.T) {
+	cmd := newRootCmd()
+	cmd.SetArgs([]string{"--hostCert", "server.pem"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "required flag")
+	assert.Contains(t, err.Error(), "hostKey")
+	assert.Contains(t, err.Error(), "rootCert")
+}
+
+func TestManagerOptions(t *testing.T) {
+	// the default limits & ceilings are always set
+	assert.Len(t, managerOptions(serverFlags{}), 2)
+	assert.Len(t, managerOptions(serverFlags{workDir: "/tmp", rootFS: "busybox.tar", cgroup: "/sys/fs/cgroup/workernator"}), 5)
+	assert.Len(t, managerOptions(serverFlags{maxRuntime: time.Hour}), 3)
+	assert.Len(t, managerOptions(serverFlags{jobUID: 100000, jobGID: 100000}), 3)
+}
+
+func TestLimitFlags(t *testing.T) {
+	cmd := newRootCmd()
+	f := cmd.Flags()
+
+	tests := map[string]string{
+		"maxPids":          "10",
+		"cpuQuota":         "200ms",
+		"cpuPeriod":        "1s",
+		"memory":           "10485760",
+		"maxPidsCeiling":   "100",
+		"cpuQuotaCeiling":  "1s",
+		"cpuPeriodCeiling": "1s",
+		"memoryCeiling":    "536870912",
+	}
+	for name, want := range tests {
+		flag := f.Lookup(name)
+		if assert.NotNil(t, flag, "flag %v", name) {
+			assert.Equal(t, want, flag.DefValue, "flag %v", name)
+		}
+	}
+
+	// the defaults have to fit under the ceilings, or no job could start
+	flags := serverFlags{defaultLimits: api.DefaultLimits, limitCeilings: defaultLimitCeilings}
+	_, err := api.NewManager(managerOptions(flags)...)
+	assert.NoError(t, err)
+}
+
+func TestLimitFlagsInvalid(t *testing.T) {
+	tests := map[string][]string{
+		"default over ceiling": {"--maxPids", "50", "--maxPidsCeiling", "20"},
+		"negative":             {"--memory", "-1"},
+		"unlimited default":    {"--memory", "0"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := newRootCmd()
+			cmd.SetArgs(append(testCertArgs(freePort(t)), args...))
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "unable to create job manager")
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	port := freePort(t)
+	flags := serverFlags{
+		host:          "localhost",
+		port:          port,
+		hostCert:      "../../internal/grpc/testdata/server.pem",
+		hostKey:       "../../internal/grpc/testdata/cakey.key",
+		rootCert:      "../../internal/grpc/testdata/ca.pem",
+		workDir:       t.TempDir(),
+		defaultLimits: api.DefaultLimits,
+		limitCeilings: defaultLimitCeilings,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- run(ctx, flags) }()
+
+	addr := net.JoinHostPort("localhost", strconv.Itoa(port))
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, 5*time.Second, 10*time.Millisecond, "server never started listening")
+
+	c, err := client.New(addr,
+		client.WithRootCert("../../internal/grpc/testdata/ca.pem"),
+		client.WithCert("../../internal/grpc/testdata/admin.pem"),
+		client.WithKey("../../internal/grpc/testdata/cakey.key"),
+	)
+	require.NoError(t, err)
+	defer c.Close()
+
+	job, err := c.Start(context.Background(), "sh", []string{"-c", "echo started; exec sleep 30"})
+	require.NoError(t, err)
+
+	// the job is watched and its output tailed while the server shuts
+	// down, which should stop the job and end both streams cleanly
+	var events []client.Event
+	watchReady := make(chan struct{})
+	watchDone := make(chan error, 1)
+	go func() {
+		watchDone <- c.Watch(context.Background(), func(ev client.Event) error {
+			events = append(events, ev)
+			return nil
+		}, client.WithWatchIDs(job.ID), client.WithReady(func() { close(watchReady) }))
+	}()
+
+	var output bytes.Buffer
+	tailReady := make(chan struct{})
+	tailDone := make(chan error, 1)
+	go func() {
+		_, err := c.Tail(context.Background(), job.ID, func(chunk client.Chunk) error {
+			if output.Len() == 0 {
+				close(tailReady)
+			}
+			output.Write(chunk.Data)
+			return nil
+		})
+		tailDone <- err
+	}()
+
+	for _, ready := range []chan struct{}{watchReady, tailReady} {
+		select {
+		case <-ready:
+		case <-time.After(5 * time.Second):
+			t.Fatal("streams never started")
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(15 * time.Second):
+		t.Fatal("server didn't shut down")
+	}
+
+	// the server only returns once every stream has ended
+	require.NoError(t, <-tailDone)
+	assert.Equal(t, "started\n", output.String())
+
+	assert.ErrorIs(t, <-watchDone, client.ErrUnavailable)
+	require.NotEmpty(t, events)
+	last := events[len(events)-1]
+	assert.Equal(t, client.EventStopped, last.Type)
+	assert.Equal(t, client.StatusStopped, last.Job.Status)
+
+	_, err = net.Dial("tcp", addr)
+	assert.Error(t, err, "server still listening after shutdown")
+}
+
+// testCertArgs returns the flags needed to start the server on port
+// using the certificates from the GRPC package tests.
+func testCertArgs(port int) []string {
+	return []string{
+		"--port", strconv.Itoa(port),
+		"--hostCert", "../../internal/grpc/testdata/server.pem",
+		"--hostKey", "../../internal/grpc/testdata/cakey.key",
+		"--rootCert", "../../internal/grpc/testdata/ca.pem",
+	}
+}
+
+// freePort returns a port nothing is listening on.
+func freePort(t *testing.T) int {
+	t.Helper()
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer lis.Close()
+	return lis.Addr().(*net.TCPAddr).Port
+}
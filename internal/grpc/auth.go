@@ -0,0 +1,183 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// permission is the level of access a user has to an RPC.
+type permission int32
+
+const (
+	// none means the user isn't allowed to use the RPC.
+	none permission = iota
+	// own means the user can use the RPC, but only to interact with
+	// their own jobs.
+	own
+	// super means the user can use the RPC to interact with any job.
+	super
+)
+
+// rpcPermissions maps RPC names to a permission. RPC names are always
+// lower-case, as they're down-cased before being looked up.
+type rpcPermissions map[string]permission
+
+// userPermissions maps user names to their permissions.
+type userPermissions map[string]rpcPermissions
+
+var superUser = rpcPermissions{
+	"start":  super,
+	"stop":   super,
+	"status": super,
+	"list":   super,
+	"watch":  super,
+	"output": super,
+	"input":  super,
+	"attach": super,
+}
+
+// permissionConfig is the hard-coded list of users and what they're
+// allowed to do. The user name comes from the L ( locality ) subject
+// field of the client certificate.
+var permissionConfig = userPermissions{
+	"admin": superUser,
+	"alice": rpcPermissions{
+		"start":  own,
+		"status": own,
+		"list":   own,
+		"watch":  own,
+		"input":  own,
+	},
+	"bob": rpcPermissions{
+		"start":  own,
+		"output": own,
+		"input":  own,
+		"attach": own,
+	},
+	"charlie": rpcPermissions{
+		"status": super,
+		"list":   super,
+		"watch":  super,
+	},
+}
+
+// UnaryAuthInterceptor returns an interceptor that only allows users
+// with permission to use an RPC to call it.
+func UnaryAuthInterceptor() grpc.UnaryServerInterceptor {
+	return authorizer{permissions: permissionConfig}.unary
+}
+
+// StreamAuthInterceptor returns an interceptor that only allows users
+// with permission to use a streaming RPC to call it.
+func StreamAuthInterceptor() grpc.StreamServerInterceptor {
+	return authorizer{permissions: permissionConfig}.stream
+}
+
+// authorizer checks requests against a set of user permissions.
+type authorizer struct {
+	permissions userPermissions
+}
+
+func (a authorizer) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a authorizer) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &callerStream{ServerStream: ss, ctx: ctx})
+}
+
+// authorize returns an error if the user making the request isn't
+// allowed to call the RPC. Otherwise it returns a context holding the
+// caller, so the RPC can limit users with the own permission to their
+// own jobs.
+func (a authorizer) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	user, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rpc := strings.ToLower(fullMethod[strings.LastIndex(fullMethod, "/")+1:])
+	perm := a.permissions[user][rpc]
+	if perm == none {
+		return nil, status.Errorf(codes.PermissionDenied, "user '%v' isn't allowed to use '%v'", user, rpc)
+	}
+	return context.WithValue(ctx, callerKey{}, caller{user: user, permission: perm}), nil
+}
+
+// caller is the authorized user making a request, along with their
+// permission for the RPC being called.
+type caller struct {
+	user       string
+	permission permission
+}
+
+type callerKey struct{}
+
+// callerFromContext returns the caller stored by the auth interceptors.
+// It returns false if the request didn't go through the interceptors.
+func callerFromContext(ctx context.Context) (caller, bool) {
+	c, ok := ctx.Value(callerKey{}).(caller)
+	return c, ok
+}
+
+// canAccess reports if the caller of a request is allowed to interact
+// with a job owned by owner. Users with the own permission can only
+// interact with jobs they started.
+func canAccess(ctx context.Context, owner string) bool {
+	c, ok := callerFromContext(ctx)
+	if !ok || c.permission != own {
+		return true
+	}
+	return c.user == owner
+}
+
+// callerStream wraps a grpc.ServerStream to replace its context with
+// one holding the caller.
+type callerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context holding the caller.
+func (s *callerStream) Context() context.Context {
+	return s.ctx
+}
+
+// userFromContext gets the user from the L subject field of the
+// verified client certificate for the request.
+func userFromContext(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "no peer information for request")
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "request wasn't made using TLS")
+	}
+
+	chains := tlsInfo.State.VerifiedChains
+	if len(chains) == 0 || len(chains[0]) == 0 {
+		return "", status.Error(codes.Unauthenticated, "no verified client certificate")
+	}
+
+	locality := chains[0][0].Subject.Locality
+	if len(locality) == 0 || locality[0] == "" {
+		return "", status.Error(codes.Unauthenticated, "client certificate has no user")
+	}
+	return locality[0], nil
+}
@@ -0,0 +1,275 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/seanhagen/workernator/internal/pb"
+	"github.com/seanhagen/workernator/internal/tlsconfig"
+)
+
+// userContext builds a context that looks like a request made using a
+// verified client certificate with the given L subject field.
+func userContext(user string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{
+		Organization:       []string{"Teleport"},
+		OrganizationalUnit: []string{"workernator"},
+		CommonName:         "client",
+	}}
+	if user != "" {
+		cert.Subject.Locality = []string{user}
+	}
+
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{cert}},
+		}},
+	})
+}
+
+func TestAuthorize(t *testing.T) {
+	a := authorizer{permissions: permissionConfig}
+
+	tests := []struct {
+		user   string
+		method string
+		want   codes.Code
+	}{
+		{"admin", "Start", codes.OK},
+		{"admin", "Stop", codes.OK},
+		{"admin", "Status", codes.OK},
+		{"admin", "Output", codes.OK},
+		{"admin", "List", codes.OK},
+		{"admin", "Attach", codes.OK},
+		{"alice", "Start", codes.OK},
+		{"alice", "Status", codes.OK},
+		{"alice", "List", codes.OK},
+		{"alice", "Input", codes.OK},
+		{"alice", "Attach", codes.PermissionDenied},
+		{"alice", "Stop", codes.PermissionDenied},
+		{"alice", "Output", codes.PermissionDenied},
+		{"bob", "Start", codes.OK},
+		{"bob", "Output", codes.OK},
+		{"bob", "Input", codes.OK},
+		{"bob", "Attach", codes.OK},
+		{"bob", "Status", codes.PermissionDenied},
+		{"bob", "List", codes.PermissionDenied},
+		{"charlie", "Status", codes.OK},
+		{"charlie", "List", codes.OK},
+		{"charlie", "Start", codes.PermissionDenied},
+		{"charlie", "Input", codes.PermissionDenied},
+		{"mallory", "Status", codes.PermissionDenied},
+		{"admin", "Unknown", codes.PermissionDenied},
+		{"", "Status", codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.user+"/"+tt.method, func(t *testing.T) {
+			_, err := a.authorize(userContext(tt.user), "/seanhagen.pb.Service/"+tt.method)
+			assert.Equal(t, tt.want, status.Code(err))
+		})
+	}
+}
+
+func TestAuthorize_CaseInsensitive(t *testing.T) {
+	a := authorizer{permissions: permissionConfig}
+
+	for _, method := range []string{"status", "Status", "sTaTuS"} {
+		_, err := a.authorize(userContext("charlie"), "/seanhagen.pb.Service/"+method)
+		assert.NoError(t, err)
+	}
+}
+
+func TestAuthorize_NoCertificate(t *testing.T) {
+	a := authorizer{permissions: permissionConfig}
+
+	_, err := a.authorize(context.Background(), "/seanhagen.pb.Service/Status")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{}})
+	_, err = a.authorize(ctx, "/seanhagen.pb.Service/Status")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAuthInterceptors(t *testing.T) {
+	addr := startTLSServer(t, serverTLS(),
+		grpc.UnaryInterceptor(UnaryAuthInterceptor()),
+		grpc.StreamInterceptor(StreamAuthInterceptor()),
+	)
+
+	// callServer uses Status, which charlie can use but bob can't
+	assert.Equal(t, codes.NotFound, callServer(t, addr, clientFor(t, "charlie")))
+	assert.Equal(t, codes.PermissionDenied, callServer(t, addr, clientFor(t, "bob")))
+
+	// the testdata client certificate doesn't have a user
+	assert.Equal(t, codes.Unauthenticated, callServer(t, addr, clientTLS()))
+}
+
+func TestAuthInterceptors_Owner(t *testing.T) {
+	addr := startTLSServer(t, serverTLS(),
+		grpc.UnaryInterceptor(UnaryAuthInterceptor()),
+		grpc.StreamInterceptor(StreamAuthInterceptor()),
+	)
+	ctx := context.Background()
+
+	alice := dialUser(t, addr, "alice")
+	bob := dialUser(t, addr, "bob")
+	charlie := dialUser(t, addr, "charlie")
+	admin := dialUser(t, addr, "admin")
+
+	aliceJob, err := alice.Start(ctx, &pb.JobStartRequest{Command: "sleep", Arguments: []string{"30"}})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", aliceJob.GetOwner())
+
+	bobJob, err := bob.Start(ctx, &pb.JobStartRequest{Command: "echo", Arguments: []string{"hi"}})
+	require.NoError(t, err)
+	assert.Equal(t, "bob", bobJob.GetOwner())
+
+	// alice can only see her own jobs, and bob's job looks like it
+	// doesn't exist rather than being forbidden
+	job, err := alice.Status(ctx, &pb.JobStatusRequest{Id: aliceJob.GetId()})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", job.GetOwner())
+	_, err = alice.Status(ctx, &pb.JobStatusRequest{Id: bobJob.GetId()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// charlie has super permission for status, so can see any job
+	_, err = charlie.Status(ctx, &pb.JobStatusRequest{Id: bobJob.GetId()})
+	assert.NoError(t, err)
+
+	// bob can only get the output of his own jobs
+	stream, err := bob.Output(ctx, &pb.OutputJobRequest{Id: aliceJob.GetId()})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	stream, err = bob.Output(ctx, &pb.OutputJobRequest{Id: bobJob.GetId()})
+	require.NoError(t, err)
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "hi\n", string(resp.GetData()))
+
+	// alice only sees her own jobs when listing, even if she asks for
+	// someone else's
+	list, err := alice.List(ctx, &pb.JobListRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetJobs(), 1)
+	assert.Equal(t, aliceJob.GetId(), list.GetJobs()[0].GetId())
+	list, err = alice.List(ctx, &pb.JobListRequest{Owner: "bob"})
+	require.NoError(t, err)
+	assert.Empty(t, list.GetJobs())
+
+	// charlie has super permission for list, so sees every job
+	list, err = charlie.List(ctx, &pb.JobListRequest{})
+	require.NoError(t, err)
+	assert.Len(t, list.GetJobs(), 2)
+
+	// the admin can stop anyone's job
+	job, err = admin.Stop(ctx, &pb.JobStopRequest{Id: aliceJob.GetId()})
+	require.NoError(t, err)
+	assert.Equal(t, pb.JobStatus_Stopped, job.GetStatus())
+}
+
+func TestAuthInterceptors_Watch(t *testing.T) {
+	addr := startTLSServer(t, serverTLS(),
+		grpc.UnaryInterceptor(UnaryAuthInterceptor()),
+		grpc.StreamInterceptor(StreamAuthInterceptor()),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	alice := dialUser(t, addr, "alice")
+	bob := dialUser(t, addr, "bob")
+
+	// bob isn't allowed to watch at all
+	stream, err := bob.Watch(ctx, &pb.JobWatchRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	stream, err = alice.Watch(ctx, &pb.JobWatchRequest{})
+	require.NoError(t, err)
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	// alice only gets events for her own jobs
+	_, err = bob.Start(ctx, &pb.JobStartRequest{Command: "true"})
+	require.NoError(t, err)
+	aliceJob, err := alice.Start(ctx, &pb.JobStartRequest{Command: "true"})
+	require.NoError(t, err)
+
+	for _, expect := range []pb.JobEventType{pb.JobEventType_JobCreated, pb.JobEventType_JobStarted, pb.JobEventType_JobFinished} {
+		ev, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, expect, ev.GetType())
+		assert.Equal(t, aliceJob.GetId(), ev.GetJob().GetId())
+	}
+}
+
+// clientFor builds a TLS config using a client certificate for user.
+func clientFor(t *testing.T, user string) tlsconfig.Config {
+	t.Helper()
+
+	conf := clientTLS()
+	conf.Cert, conf.Key = signedCert(t, pkix.Name{
+		Organization:       []string{"Teleport"},
+		OrganizationalUnit: []string{"workernator"},
+		CommonName:         "client",
+		Locality:           []string{user},
+	}, x509.ExtKeyUsageClientAuth)
+	return conf
+}
+
+// dialUser connects to the server at addr as user.
+func dialUser(t *testing.T, addr, user string) pb.ServiceClient {
+	t.Helper()
+
+	creds, err := tlsconfig.ClientCredentials(clientFor(t, user))
+	require.NoError(t, err)
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(creds))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return pb.NewServiceClient(conn)
+}
+
+func TestStreamAuthInterceptor(t *testing.T) {
+	interceptor := StreamAuthInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/seanhagen.pb.Service/Output"}
+
+	called := false
+	handler := func(interface{}, grpc.ServerStream) error {
+		called = true
+		return nil
+	}
+
+	err := interceptor(nil, fakeStream{ctx: userContext("alice")}, info, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.False(t, called)
+
+	err = interceptor(nil, fakeStream{ctx: userContext("bob")}, info, handler)
+	require.NoError(t, err)
+	assert.True(t, called)
+}
+
+// fakeStream is a grpc.ServerStream that only has a context.
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s fakeStream) Context() context.Context {
+	return s.ctx
+}
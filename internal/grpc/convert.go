@@ -0,0 +1,222 @@
+package grpc
+
+import (
+	"fmt"
+
+	"github.com/rs/xid"
+	"golang.org/x/sys/unix"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/seanhagen/workernator/internal/pb"
+	"github.com/seanhagen/workernator/library/api"
+)
+
+// statusToPB maps job manager statuses to their GRPC equivalent.
+var statusToPB = map[api.JobStatus]pb.JobStatus{
+	api.StatusUnknown:  pb.JobStatus_Unknown,
+	api.StatusRunning:  pb.JobStatus_Running,
+	api.StatusFailed:   pb.JobStatus_Failed,
+	api.StatusFinished: pb.JobStatus_Finished,
+	api.StatusStopped:  pb.JobStatus_Stopped,
+	api.StatusTimedOut: pb.JobStatus_TimedOut,
+}
+
+// statusFromPB maps GRPC job statuses to their job manager equivalent.
+var statusFromPB = map[pb.JobStatus]api.JobStatus{
+	pb.JobStatus_Unknown:  api.StatusUnknown,
+	pb.JobStatus_Running:  api.StatusRunning,
+	pb.JobStatus_Failed:   api.StatusFailed,
+	pb.JobStatus_Finished: api.StatusFinished,
+	pb.JobStatus_Stopped:  api.StatusStopped,
+	pb.JobStatus_TimedOut: api.StatusTimedOut,
+}
+
+// reasonToPB maps job manager termination reasons to their GRPC
+// equivalent.
+var reasonToPB = map[api.TerminationReason]pb.TerminationReason{
+	api.ReasonNone:          pb.TerminationReason_NotTerminated,
+	api.ReasonExited:        pb.TerminationReason_Exited,
+	api.ReasonSignaled:      pb.TerminationReason_Signaled,
+	api.ReasonUserStopped:   pb.TerminationReason_UserStopped,
+	api.ReasonOOMKilled:     pb.TerminationReason_OOMKilled,
+	api.ReasonPidsExhausted: pb.TerminationReason_PidsExhausted,
+	api.ReasonTimeout:       pb.TerminationReason_Timeout,
+}
+
+// eventTypeToPB maps job manager event types to their GRPC equivalent.
+var eventTypeToPB = map[api.EventType]pb.JobEventType{
+	api.EventCreated:  pb.JobEventType_JobCreated,
+	api.EventStarted:  pb.JobEventType_JobStarted,
+	api.EventStopped:  pb.JobEventType_JobStopped,
+	api.EventTimedOut: pb.JobEventType_JobTimedOut,
+	api.EventFinished: pb.JobEventType_JobFinished,
+	api.EventFailed:   pb.JobEventType_JobFailed,
+}
+
+// streamToPB maps job manager output streams to their GRPC equivalent.
+var streamToPB = map[api.Stream]pb.OutputStream{
+	api.StreamStdout: pb.OutputStream_Stdout,
+	api.StreamStderr: pb.OutputStream_Stderr,
+}
+
+// streamFromPB maps GRPC output streams to their job manager
+// equivalent.
+var streamFromPB = map[pb.OutputStream]api.Stream{
+	pb.OutputStream_Stdout: api.StreamStdout,
+	pb.OutputStream_Stderr: api.StreamStderr,
+}
+
+// eventToPB converts a job manager event into the GRPC JobEvent
+// message.
+func eventToPB(ev api.Event) *pb.JobEvent {
+	return &pb.JobEvent{
+		Type: eventTypeToPB[ev.Type],
+		Job:  jobToPB(ev.Job),
+		Time: timestamppb.New(ev.At),
+	}
+}
+
+// jobToPB converts the job info returned by the job manager into the
+// GRPC Job message.
+func jobToPB(info *api.JobInfo) *pb.Job {
+	job := &pb.Job{
+		Id:       info.ID,
+		Owner:    info.Owner,
+		Status:   statusToPB[info.Status],
+		Command:  info.Command,
+		Args:     info.Args,
+		ErrorMsg: info.ErrorMsg,
+		Limits:   limitsToPB(info.Limits),
+		Tty:      info.TTY,
+	}
+
+	if info.MaxRuntime != 0 {
+		job.MaxRuntime = durationpb.New(info.MaxRuntime)
+	}
+	if info.Signal != 0 {
+		job.Signal = unix.SignalName(info.Signal)
+	}
+
+	if !info.StartedAt.IsZero() {
+		job.StartedAt = timestamppb.New(info.StartedAt)
+	}
+	if !info.EndedAt.IsZero() {
+		job.EndedAt = timestamppb.New(info.EndedAt)
+		job.TerminationReason = reasonToPB[info.Reason]
+		job.ExitCode = int32(info.ExitCode)
+		job.Usage = usageToPB(info.Usage)
+	}
+
+	return job
+}
+
+// usageToPB converts job manager resource usage into the GRPC
+// ResourceUsage message.
+func usageToPB(usage api.ResourceUsage) *pb.ResourceUsage {
+	return &pb.ResourceUsage{
+		UserCpu:         durationpb.New(usage.UserCPU),
+		SystemCpu:       durationpb.New(usage.SystemCPU),
+		PeakMemoryBytes: usage.PeakMemoryBytes,
+		IoReadBytes:     usage.IOReadBytes,
+		IoWriteBytes:    usage.IOWriteBytes,
+	}
+}
+
+// limitsToPB converts job manager limits into the GRPC ResourceLimits
+// message.
+func limitsToPB(limits api.Limits) *pb.ResourceLimits {
+	out := &pb.ResourceLimits{
+		MaxPids:     limits.MaxPids,
+		MemoryBytes: limits.MemoryBytes,
+	}
+
+	if limits.CPUQuota > 0 {
+		out.CpuQuota = durationpb.New(limits.CPUQuota)
+	}
+	if limits.CPUPeriod > 0 {
+		out.CpuPeriod = durationpb.New(limits.CPUPeriod)
+	}
+
+	for _, io := range limits.IO {
+		out.Io = append(out.Io, &pb.IOLimit{
+			Device:           io.Device,
+			ReadBytesPerSec:  io.ReadBPS,
+			WriteBytesPerSec: io.WriteBPS,
+		})
+	}
+
+	return out
+}
+
+// limitsFromPB converts the GRPC ResourceLimits message into limits
+// for the job manager. A nil message results in zero limits, which
+// means the job uses the defaults configured in the manager.
+func limitsFromPB(limits *pb.ResourceLimits) (api.Limits, error) {
+	out := api.Limits{
+		MaxPids:     limits.GetMaxPids(),
+		MemoryBytes: limits.GetMemoryBytes(),
+	}
+
+	if limits.GetCpuQuota() != nil {
+		if err := limits.GetCpuQuota().CheckValid(); err != nil {
+			return out, fmt.Errorf("cpu quota: %w", err)
+		}
+		out.CPUQuota = limits.GetCpuQuota().AsDuration()
+	}
+	if limits.GetCpuPeriod() != nil {
+		if err := limits.GetCpuPeriod().CheckValid(); err != nil {
+			return out, fmt.Errorf("cpu period: %w", err)
+		}
+		out.CPUPeriod = limits.GetCpuPeriod().AsDuration()
+	}
+
+	for _, io := range limits.GetIo() {
+		out.IO = append(out.IO, api.IOLimit{
+			Device:   io.GetDevice(),
+			ReadBPS:  io.GetReadBytesPerSec(),
+			WriteBPS: io.GetWriteBytesPerSec(),
+		})
+	}
+
+	return out, nil
+}
+
+// filterFromPB converts the filters in the GRPC JobListRequest message
+// into a job filter for the job manager.
+func filterFromPB(req *pb.JobListRequest) (api.JobFilter, error) {
+	filter := api.JobFilter{
+		Owner:   req.GetOwner(),
+		Command: req.GetCommand(),
+	}
+
+	for _, st := range req.GetStatuses() {
+		s, ok := statusFromPB[st]
+		if !ok {
+			return filter, fmt.Errorf("unknown status %v", st)
+		}
+		filter.Statuses = append(filter.Statuses, s)
+	}
+
+	if req.GetStartedAfter() != nil {
+		if err := req.GetStartedAfter().CheckValid(); err != nil {
+			return filter, fmt.Errorf("started after: %w", err)
+		}
+		filter.StartedAfter = req.GetStartedAfter().AsTime()
+	}
+	if req.GetStartedBefore() != nil {
+		if err := req.GetStartedBefore().CheckValid(); err != nil {
+			return filter, fmt.Errorf("started before: %w", err)
+		}
+		filter.StartedBefore = req.GetStartedBefore().AsTime()
+	}
+
+	if req.GetPageToken() != "" {
+		if _, err := xid.FromString(req.GetPageToken()); err != nil {
+			return filter, fmt.Errorf("page token: %w", err)
+		}
+		filter.AfterID = req.GetPageToken()
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/seanhagen/workernator/internal/pb"
+	"github.com/seanhagen/workernator/library/api"
+)
+
+func TestJobToPB(t *testing.T) {
+	started := time.Date(2022, 7, 7, 16, 34, 3, 0, time.UTC)
+	ended := started.Add(time.Second)
+
+	info := &api.JobInfo{
+		ID:        "abc",
+		Owner:     "alice",
+		Status:    api.StatusFailed,
+		Command:   "fib",
+		Args:      []string{"3"},
+		ErrorMsg:  "exit status 1",
+		Limits:    api.Limits{MaxPids: 10, CPUQuota: 200 * time.Millisecond},
+		Signal:    syscall.SIGTERM,
+		Reason:    api.ReasonOOMKilled,
+		ExitCode:  -1,
+		Usage:     api.ResourceUsage{UserCPU: time.Second, PeakMemoryBytes: 1024, IOWriteBytes: 512},
+		StartedAt: started,
+		EndedAt:   ended,
+
+		MaxRuntime: time.Minute,
+	}
+
+	usage := &pb.ResourceUsage{
+		UserCpu:         durationpb.New(time.Second),
+		SystemCpu:       durationpb.New(0),
+		PeakMemoryBytes: 1024,
+		IoWriteBytes:    512,
+	}
+	expect := &pb.Job{
+		Id:        "abc",
+		Owner:     "alice",
+		Status:    pb.JobStatus_Failed,
+		Command:   "fib",
+		Args:      []string{"3"},
+		ErrorMsg:  "exit status 1",
+		Limits:    &pb.ResourceLimits{MaxPids: 10, CpuQuota: durationpb.New(200 * time.Millisecond)},
+		Signal:    "SIGTERM",
+		ExitCode:  -1,
+		Usage:     usage,
+		StartedAt: timestamppb.New(started),
+		EndedAt:   timestamppb.New(ended),
+
+		TerminationReason: pb.TerminationReason_OOMKilled,
+		MaxRuntime:        durationpb.New(time.Minute),
+	}
+	assert.True(t, proto.Equal(expect, jobToPB(info)), "got %v", jobToPB(info))
+
+	// a running job has no end time
+	info.Status = api.StatusRunning
+	info.EndedAt = time.Time{}
+	got := jobToPB(info)
+	assert.Equal(t, pb.JobStatus_Running, got.GetStatus())
+	assert.Nil(t, got.GetEndedAt())
+	assert.Nil(t, got.GetUsage())
+}
+
+func TestLimitsRoundTrip(t *testing.T) {
+	limits := api.Limits{
+		MaxPids:     10,
+		CPUQuota:    200 * time.Millisecond,
+		CPUPeriod:   time.Second,
+		MemoryBytes: 1024,
+		IO:          []api.IOLimit{{Device: "8:0", ReadBPS: 1, WriteBPS: 2}},
+	}
+
+	got, err := limitsFromPB(limitsToPB(limits))
+	require.NoError(t, err)
+	assert.Equal(t, limits, got)
+
+	got, err = limitsFromPB(nil)
+	require.NoError(t, err)
+	assert.Equal(t, api.Limits{}, got)
+}
@@ -0,0 +1,14 @@
+package grpc
+
+import (
+	"os"
+	"testing"
+
+	"github.com/seanhagen/workernator/library/api"
+)
+
+func TestMain(m *testing.M) {
+	// jobs are started by re-executing the test binary
+	api.Init()
+	os.Exit(m.Run())
+}
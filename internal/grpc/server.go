@@ -0,0 +1,489 @@
+// Package grpc contains the GRPC service for workernator, which is a
+// thin wrapper around the job manager in library/api.
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/seanhagen/workernator/internal/pb"
+	"github.com/seanhagen/workernator/library/api"
+)
+
+// outputChunkSize is the maximum number of bytes sent in each message
+// streamed by Output.
+const outputChunkSize = 4096
+
+const (
+	// defaultPageSize is the number of jobs returned by List when the
+	// request doesn't set a page size.
+	defaultPageSize = 50
+	// maxPageSize is the most jobs List returns at once.
+	maxPageSize = 500
+)
+
+// Server implements pb.ServiceServer using a job manager.
+type Server struct {
+	pb.UnimplementedServiceServer
+
+	manager *api.Manager
+}
+
+// NewServer builds a Server that uses manager to run jobs.
+func NewServer(manager *api.Manager) *Server {
+	return &Server{manager: manager}
+}
+
+// Start creates a job and starts running it. The user making the
+// request is recorded as the owner of the job.
+func (s *Server) Start(ctx context.Context, req *pb.JobStartRequest) (*pb.Job, error) {
+	limits, err := limitsFromPB(req.GetLimits())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid limits: %v", err)
+	}
+
+	maxRuntime, err := durationFromPB(req.GetMaxRuntime(), "max runtime")
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []api.JobOption{api.WithLimits(limits), api.WithMaxRuntime(maxRuntime)}
+	if req.GetStdin() {
+		opts = append(opts, api.WithStdin())
+	}
+	if req.GetTty() {
+		opts = append(opts, api.WithTTY())
+	}
+	if c, ok := callerFromContext(ctx); ok {
+		opts = append(opts, api.WithOwner(c.user))
+	}
+
+	info, err := s.manager.StartJob(req.GetCommand(), req.GetArguments(), opts...)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return jobToPB(info), nil
+}
+
+// Stop stops a job, waiting until it has stopped or the request is
+// cancelled before returning. A grace period in the request lets the job
+// exit cleanly before it's killed.
+func (s *Server) Stop(ctx context.Context, req *pb.JobStopRequest) (*pb.Job, error) {
+	if err := s.checkAccess(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	gracePeriod, err := durationFromPB(req.GetGracePeriod(), "grace period")
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.manager.StopJob(ctx, req.GetId(), api.WithGracePeriod(gracePeriod))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return jobToPB(info), nil
+}
+
+// Status returns the current state of a job.
+func (s *Server) Status(ctx context.Context, req *pb.JobStatusRequest) (*pb.Job, error) {
+	info, err := s.manager.JobStatus(req.GetId())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	if !canAccess(ctx, info.Owner) {
+		return nil, notFound(req.GetId())
+	}
+	return jobToPB(info), nil
+}
+
+// List returns a page of the jobs that match the filters in the
+// request. Callers with the own permission only see their own jobs.
+func (s *Server) List(ctx context.Context, req *pb.JobListRequest) (*pb.JobListResponse, error) {
+	filter, err := filterFromPB(req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid filter: %v", err)
+	}
+
+	if c, ok := callerFromContext(ctx); ok && c.permission == own {
+		if filter.Owner != "" && filter.Owner != c.user {
+			return &pb.JobListResponse{}, nil
+		}
+		filter.Owner = c.user
+	}
+
+	pageSize := int(req.GetPageSize())
+	switch {
+	case pageSize < 0:
+		return nil, status.Error(codes.InvalidArgument, "page size can't be negative")
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+	// asking for one more job than will be returned shows if there's
+	// another page
+	filter.Limit = pageSize + 1
+
+	jobs := s.manager.ListJobs(filter)
+	resp := &pb.JobListResponse{}
+	if len(jobs) > pageSize {
+		jobs = jobs[:pageSize]
+		resp.NextPageToken = jobs[pageSize-1].ID
+	}
+	for _, info := range jobs {
+		resp.Jobs = append(resp.Jobs, jobToPB(info))
+	}
+	return resp, nil
+}
+
+// Watch streams events for jobs that match the filters in the request,
+// until the client cancels the request or the manager is shut down.
+// Callers with the own permission only get events for their own jobs.
+func (s *Server) Watch(req *pb.JobWatchRequest, stream pb.Service_WatchServer) error {
+	ctx := stream.Context()
+
+	ids := map[string]bool{}
+	for _, id := range req.GetIds() {
+		ids[id] = true
+	}
+
+	sub, err := s.manager.Subscribe(ctx)
+	if err != nil {
+		return toStatusError(err)
+	}
+	// sending the headers straight away lets clients know they won't
+	// miss any events from now on
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	for ev := range sub.Events() {
+		if len(ids) > 0 && !ids[ev.Job.ID] {
+			continue
+		}
+		if req.GetOwner() != "" && ev.Job.Owner != req.GetOwner() {
+			continue
+		}
+		if !canAccess(ctx, ev.Job.Owner) {
+			continue
+		}
+		if err := stream.Send(eventToPB(ev)); err != nil {
+			return err
+		}
+	}
+	return toStatusError(sub.Err())
+}
+
+// Output streams the output of a job, by default from the beginning
+// until the job has ended and all of the output has been sent. Each
+// chunk has its offset in the output, so clients can carry on from
+// where they were if they're disconnected.
+func (s *Server) Output(req *pb.OutputJobRequest, stream pb.Service_OutputServer) error {
+	if err := s.checkAccess(stream.Context(), req.GetId()); err != nil {
+		return err
+	}
+
+	opts := []api.TailOption{api.WithOffset(req.GetStartOffset())}
+	if req.TailLines != nil {
+		opts = append(opts, api.WithLastLines(int(req.GetTailLines())))
+	}
+	if req.Follow != nil {
+		opts = append(opts, api.WithFollow(req.GetFollow()))
+	}
+	if req.GetStream() != pb.OutputStream_Combined {
+		only, ok := streamFromPB[req.GetStream()]
+		if !ok {
+			return status.Errorf(codes.InvalidArgument, "unknown output stream %v", req.GetStream())
+		}
+		opts = append(opts, api.WithStream(only))
+	}
+
+	r, err := s.manager.TailJob(stream.Context(), req.GetId(), opts...)
+	if err != nil {
+		return toStatusError(err)
+	}
+	defer r.Close()
+
+	buf := make([]byte, outputChunkSize)
+	for {
+		chunk, err := r.ReadChunk(buf)
+		if len(chunk.Data) > 0 {
+			resp := &pb.OutputJobResponse{
+				Data:   chunk.Data,
+				Offset: chunk.Offset,
+				Stream: streamToPB[chunk.Stream],
+			}
+			if req.GetTimestamps() {
+				resp.Time = timestamppb.New(chunk.At)
+			}
+			if sendErr := stream.Send(resp); sendErr != nil {
+				return sendErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return toStatusError(err)
+		}
+	}
+}
+
+// Input writes the data sent by the client to the stdin of a job,
+// closing stdin once the client has sent everything.
+func (s *Server) Input(stream pb.Service_InputServer) error {
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, "no job ID sent")
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.checkAccess(stream.Context(), first.GetId()); err != nil {
+		return err
+	}
+
+	r := &inputReader{stream: stream, data: first.GetData(), keepOpen: first.GetKeepOpen()}
+	n, err := s.manager.SendInput(stream.Context(), first.GetId(), r)
+	if err != nil && !errors.Is(err, errKeepOpen) {
+		return toStatusError(err)
+	}
+	return stream.SendAndClose(&pb.JobInputResponse{BytesWritten: n})
+}
+
+// errKeepOpen is returned by inputReader once it has read a message with
+// keep_open set, so that the manager doesn't close stdin.
+var errKeepOpen = errors.New("input ended without closing stdin")
+
+// inputReader reads the data sent to Input, returning io.EOF once the
+// client has sent everything.
+type inputReader struct {
+	stream pb.Service_InputServer
+	// data is what's left of the last message received.
+	data []byte
+	// keepOpen is set if the last message received had keep_open set.
+	keepOpen bool
+}
+
+// Read implements io.Reader.
+func (r *inputReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		if r.keepOpen {
+			return 0, errKeepOpen
+		}
+		req, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.data, r.keepOpen = req.GetData(), req.GetKeepOpen()
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// Attach connects the client to the terminal of a job, until the job
+// ends or the client detaches by closing its side of the stream.
+func (s *Server) Attach(stream pb.Service_AttachServer) error {
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, "no job ID sent")
+	}
+	if err != nil {
+		return err
+	}
+	id := first.GetId()
+	if err := s.checkAccess(stream.Context(), id); err != nil {
+		return err
+	}
+	info, err := s.manager.JobStatus(id)
+	if err != nil {
+		return toStatusError(err)
+	}
+	if !info.TTY {
+		return toStatusError(fmt.Errorf("%w: job '%v' wasn't started with a terminal", api.ErrNoTerminal, id))
+	}
+
+	in := &attachReader{stream: stream, manager: s.manager, id: id}
+	if err := in.handle(first); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	// only output written from now on is sent, the same as if the client
+	// had been looking at the terminal all along
+	r, err := s.manager.TailJob(ctx, id, api.WithLastLines(0))
+	if err != nil {
+		return toStatusError(err)
+	}
+	defer r.Close()
+
+	inputErr := make(chan error, 1)
+	go func() {
+		_, err := s.manager.SendInput(ctx, id, in)
+		if errors.Is(err, errAttachEnded) {
+			err = in.err
+		}
+		inputErr <- err
+
+		// once the job has ended the rest of its output is still sent,
+		// otherwise there's no point sending output nobody can reply to
+		if err != nil {
+			if info, statusErr := s.manager.JobStatus(id); statusErr == nil && info.Status != api.StatusRunning {
+				return
+			}
+		}
+		cancel()
+	}()
+
+	buf := make([]byte, outputChunkSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if sendErr := stream.Send(&pb.AttachResponse{Output: buf[:n]}); sendErr != nil {
+				return sendErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			select {
+			case inErr := <-inputErr:
+				// a nil error means the client detached
+				if inErr == nil {
+					return nil
+				}
+				return toStatusError(inErr)
+			default:
+				return toStatusError(err)
+			}
+		}
+	}
+}
+
+// errAttachEnded is returned by attachReader once the client has
+// detached, or the stream has failed, so that the manager stops sending
+// input.
+var errAttachEnded = errors.New("attach ended")
+
+// attachReader reads the input sent to Attach, changing the window size
+// of the terminal of the job whenever the client asks.
+type attachReader struct {
+	stream  pb.Service_AttachServer
+	manager *api.Manager
+	id      string
+	// data is what's left of the input from the last message received.
+	data []byte
+	// err is why the attach ended once errAttachEnded has been returned,
+	// which is nil if the client detached.
+	err error
+}
+
+// Read implements io.Reader.
+func (r *attachReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		req, err := r.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return 0, errAttachEnded
+		}
+		if err == nil {
+			err = r.handle(req)
+		}
+		if err != nil {
+			r.err = err
+			return 0, errAttachEnded
+		}
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// handle resizes the terminal if the message asks for it, then keeps the
+// input from the message to be read.
+func (r *attachReader) handle(req *pb.AttachRequest) error {
+	if size := req.GetResize(); size != nil {
+		if size.GetRows() > math.MaxUint16 || size.GetCols() > math.MaxUint16 {
+			return status.Errorf(codes.InvalidArgument, "invalid window size %vx%v", size.GetCols(), size.GetRows())
+		}
+		if err := r.manager.ResizeTerminal(r.id, uint16(size.GetRows()), uint16(size.GetCols())); err != nil {
+			return toStatusError(err)
+		}
+	}
+	r.data = req.GetInput()
+	return nil
+}
+
+// checkAccess returns an error if the caller isn't allowed to interact
+// with the job with the given ID.
+func (s *Server) checkAccess(ctx context.Context, id string) error {
+	info, err := s.manager.JobStatus(id)
+	if err != nil {
+		return toStatusError(err)
+	}
+	if !canAccess(ctx, info.Owner) {
+		return notFound(id)
+	}
+	return nil
+}
+
+// notFound builds the error returned when a job doesn't exist. It's
+// also used for jobs the caller isn't allowed to see, so that users
+// can't find out about jobs that belong to someone else.
+func notFound(id string) error {
+	return toStatusError(fmt.Errorf("%w: '%v'", api.ErrJobNotFound, id))
+}
+
+// durationFromPB converts an optional duration from a request, which
+// must be valid and not negative. A nil duration results in zero. name is
+// used to describe the field in the error returned.
+func durationFromPB(d *durationpb.Duration, name string) (time.Duration, error) {
+	if d == nil {
+		return 0, nil
+	}
+	if err := d.CheckValid(); err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid %v: %v", name, err)
+	}
+	if d.AsDuration() < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "%v can't be negative", name)
+	}
+	return d.AsDuration(), nil
+}
+
+// toStatusError converts errors from the job manager into GRPC status
+// errors with an appropriate code.
+func toStatusError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, api.ErrJobNotFound):
+		code = codes.NotFound
+	case errors.Is(err, api.ErrInvalidCommand), errors.Is(err, api.ErrInvalidLimits), errors.Is(err, api.ErrInvalidTail):
+		code = codes.InvalidArgument
+	case errors.Is(err, api.ErrManagerClosed):
+		code = codes.Unavailable
+	case errors.Is(err, api.ErrSubscriberTooSlow):
+		code = codes.Aborted
+	case errors.Is(err, api.ErrNoInput), errors.Is(err, api.ErrNoTerminal):
+		code = codes.FailedPrecondition
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,623 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/seanhagen/workernator/internal/pb"
+	"github.com/seanhagen/workernator/library/api"
+)
+
+// newTestClient starts a server using a new job manager, and returns a
+// client connected to it.
+func newTestClient(t *testing.T, opts ...api.Option) pb.ServiceClient {
+	t.Helper()
+	client, _ := newTestServer(t, opts...)
+	return client
+}
+
+// newTestServer is newTestClient, but also returns the job manager used
+// by the server.
+func newTestServer(t *testing.T, opts ...api.Option) (pb.ServiceClient, *api.Manager) {
+	t.Helper()
+
+	opts = append([]api.Option{api.WithWorkDir(t.TempDir())}, opts...)
+	manager, err := api.NewManager(opts...)
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer()
+	pb.RegisterServiceServer(srv, NewServer(manager))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return pb.NewServiceClient(conn), manager
+}
+
+func waitForJob(t *testing.T, client pb.ServiceClient, id string) *pb.Job {
+	t.Helper()
+	var job *pb.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = client.Status(context.Background(), &pb.JobStatusRequest{Id: id})
+		require.NoError(t, err)
+		return job.GetStatus() != pb.JobStatus_Running
+	}, 15*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestServer_StartStatus(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	job, err := client.Start(ctx, &pb.JobStartRequest{Command: "echo", Arguments: []string{"hi"}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, job.GetId())
+	assert.Equal(t, pb.JobStatus_Running, job.GetStatus())
+	assert.Equal(t, "echo", job.GetCommand())
+	assert.Equal(t, []string{"hi"}, job.GetArgs())
+	assert.NotNil(t, job.GetStartedAt())
+	assert.Nil(t, job.GetEndedAt())
+
+	job = waitForJob(t, client, job.GetId())
+	assert.Equal(t, pb.JobStatus_Finished, job.GetStatus())
+	assert.NotNil(t, job.GetEndedAt())
+	assert.False(t, job.GetEndedAt().AsTime().Before(job.GetStartedAt().AsTime()))
+}
+
+func TestServer_Stop(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	job, err := client.Start(ctx, &pb.JobStartRequest{Command: "sleep", Arguments: []string{"30"}})
+	require.NoError(t, err)
+
+	job, err = client.Stop(ctx, &pb.JobStopRequest{Id: job.GetId()})
+	require.NoError(t, err)
+	assert.Equal(t, pb.JobStatus_Stopped, job.GetStatus())
+	assert.Equal(t, "SIGKILL", job.GetSignal())
+	assert.NotNil(t, job.GetEndedAt())
+}
+
+func TestServer_StopGracePeriod(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	// sleep is the init process of the job, and the kernel ignores
+	// signals it doesn't handle, so it has to be killed
+	job, err := client.Start(ctx, &pb.JobStartRequest{Command: "sleep", Arguments: []string{"30"}})
+	require.NoError(t, err)
+
+	_, err = client.Stop(ctx, &pb.JobStopRequest{Id: job.GetId(), GracePeriod: durationpb.New(-time.Second)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	start := time.Now()
+	job, err = client.Stop(ctx, &pb.JobStopRequest{Id: job.GetId(), GracePeriod: durationpb.New(200 * time.Millisecond)})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	assert.Equal(t, pb.JobStatus_Stopped, job.GetStatus())
+	assert.Equal(t, "SIGKILL", job.GetSignal())
+}
+
+func TestServer_StartMaxRuntime(t *testing.T) {
+	client := newTestClient(t, api.WithMaxRuntimeCeiling(time.Minute))
+	ctx := context.Background()
+
+	_, err := client.Start(ctx, &pb.JobStartRequest{Command: "true", MaxRuntime: durationpb.New(-time.Second)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.Start(ctx, &pb.JobStartRequest{Command: "true", MaxRuntime: durationpb.New(time.Hour)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	script := `trap 'exit 0' TERM; while true; do sleep 0.1; done`
+	job, err := client.Start(ctx, &pb.JobStartRequest{
+		Command:    "sh",
+		Arguments:  []string{"-c", script},
+		MaxRuntime: durationpb.New(200 * time.Millisecond),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 200*time.Millisecond, job.GetMaxRuntime().AsDuration())
+
+	job = waitForJob(t, client, job.GetId())
+	assert.Equal(t, pb.JobStatus_TimedOut, job.GetStatus())
+	assert.Equal(t, pb.TerminationReason_Timeout, job.GetTerminationReason())
+}
+
+func TestServer_List(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		job, err := client.Start(ctx, &pb.JobStartRequest{Command: "sleep", Arguments: []string{"30"}})
+		require.NoError(t, err)
+		ids = append(ids, job.GetId())
+	}
+	job, err := client.Start(ctx, &pb.JobStartRequest{Command: "true"})
+	require.NoError(t, err)
+	waitForJob(t, client, job.GetId())
+
+	// every page but the last has a token for the next page
+	var got []string
+	req := &pb.JobListRequest{Statuses: []pb.JobStatus{pb.JobStatus_Running}, PageSize: 2}
+	for {
+		resp, err := client.List(ctx, req)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(resp.GetJobs()), 2)
+		for _, job := range resp.GetJobs() {
+			got = append(got, job.GetId())
+		}
+		if resp.GetNextPageToken() == "" {
+			break
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+	assert.Equal(t, ids, got)
+
+	resp, err := client.List(ctx, &pb.JobListRequest{Command: "true"})
+	require.NoError(t, err)
+	require.Len(t, resp.GetJobs(), 1)
+	assert.Equal(t, job.GetId(), resp.GetJobs()[0].GetId())
+	assert.Empty(t, resp.GetNextPageToken())
+
+	resp, err = client.List(ctx, &pb.JobListRequest{StartedAfter: timestamppb.New(time.Now().Add(time.Hour))})
+	require.NoError(t, err)
+	assert.Empty(t, resp.GetJobs())
+
+	for _, id := range ids {
+		_, err := client.Stop(ctx, &pb.JobStopRequest{Id: id})
+		require.NoError(t, err)
+	}
+}
+
+func TestServer_ListInvalid(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	tests := map[string]*pb.JobListRequest{
+		"page token": {PageToken: "not-a-token"},
+		"page size":  {PageSize: -1},
+		"status":     {Statuses: []pb.JobStatus{pb.JobStatus(99)}},
+	}
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := client.List(ctx, req)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestServer_Watch(t *testing.T) {
+	client, manager := newTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watched, err := client.Start(ctx, &pb.JobStartRequest{Command: "sleep", Arguments: []string{"30"}})
+	require.NoError(t, err)
+
+	stream, err := client.Watch(ctx, &pb.JobWatchRequest{Ids: []string{watched.GetId()}})
+	require.NoError(t, err)
+	// the headers are sent once the server is subscribed to events
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	other, err := client.Start(ctx, &pb.JobStartRequest{Command: "true"})
+	require.NoError(t, err)
+	waitForJob(t, client, other.GetId())
+
+	_, err = client.Stop(ctx, &pb.JobStopRequest{Id: watched.GetId()})
+	require.NoError(t, err)
+
+	// events for the other jobs are filtered out
+	ev, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, pb.JobEventType_JobStopped, ev.GetType())
+	assert.Equal(t, watched.GetId(), ev.GetJob().GetId())
+	assert.Equal(t, pb.JobStatus_Stopped, ev.GetJob().GetStatus())
+	assert.NotNil(t, ev.GetTime())
+
+	// shutting down ends the stream, so clients know to reconnect
+	require.NoError(t, manager.Shutdown())
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestServer_Output(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	job, err := client.Start(ctx, &pb.JobStartRequest{
+		Command:   "../../library/api/testdata/slow_output.sh",
+		Arguments: []string{"grpc"},
+	})
+	require.NoError(t, err)
+
+	stream, err := client.Output(ctx, &pb.OutputJobRequest{Id: job.GetId()})
+	require.NoError(t, err)
+
+	var got []byte
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(got)), resp.GetOffset())
+		got = append(got, resp.GetData()...)
+	}
+	assert.Equal(t, "hello grpc\nhere i am\nanother line\nboop\nall done!\n", string(got))
+
+	job, err = client.Status(ctx, &pb.JobStatusRequest{Id: job.GetId()})
+	require.NoError(t, err)
+	assert.Equal(t, pb.JobStatus_Finished, job.GetStatus())
+}
+
+func TestServer_OutputOptions(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	job, err := client.Start(ctx, &pb.JobStartRequest{
+		Command:   "sh",
+		Arguments: []string{"-c", `printf 'one\ntwo\nthree\n'; sleep 30`},
+	})
+	require.NoError(t, err)
+	defer func() { _, _ = client.Stop(ctx, &pb.JobStopRequest{Id: job.GetId()}) }()
+
+	// the job is still running, so the output only ends because it's not
+	// being followed
+	read := func(req *pb.OutputJobRequest) (string, int64) {
+		t.Helper()
+		req.Id = job.GetId()
+		req.Follow = proto.Bool(false)
+		stream, err := client.Output(ctx, req)
+		require.NoError(t, err)
+
+		var got []byte
+		first := int64(-1)
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return string(got), first
+			}
+			require.NoError(t, err)
+			if first < 0 {
+				first = resp.GetOffset()
+			}
+			got = append(got, resp.GetData()...)
+		}
+	}
+
+	require.Eventually(t, func() bool {
+		out, _ := read(&pb.OutputJobRequest{})
+		return out == "one\ntwo\nthree\n"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	out, offset := read(&pb.OutputJobRequest{StartOffset: 4})
+	assert.Equal(t, "two\nthree\n", out)
+	assert.Equal(t, int64(4), offset)
+
+	out, offset = read(&pb.OutputJobRequest{TailLines: proto.Int32(1)})
+	assert.Equal(t, "three\n", out)
+	assert.Equal(t, int64(8), offset)
+
+	out, _ = read(&pb.OutputJobRequest{TailLines: proto.Int32(0)})
+	assert.Empty(t, out)
+
+	stream, err := client.Output(ctx, &pb.OutputJobRequest{Id: job.GetId(), StartOffset: 4, TailLines: proto.Int32(1)})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_OutputStreams(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	job, err := client.Start(ctx, &pb.JobStartRequest{
+		Command:   "sh",
+		Arguments: []string{"-c", `echo out; echo err >&2`},
+	})
+	require.NoError(t, err)
+
+	read := func(only pb.OutputStream) map[pb.OutputStream]string {
+		t.Helper()
+		stream, err := client.Output(ctx, &pb.OutputJobRequest{Id: job.GetId(), Stream: only})
+		require.NoError(t, err)
+
+		got := map[pb.OutputStream]string{}
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return got
+			}
+			require.NoError(t, err)
+			got[resp.GetStream()] += string(resp.GetData())
+		}
+	}
+
+	assert.Equal(t, map[pb.OutputStream]string{
+		pb.OutputStream_Stdout: "out\n",
+		pb.OutputStream_Stderr: "err\n",
+	}, read(pb.OutputStream_Combined))
+	assert.Equal(t, map[pb.OutputStream]string{pb.OutputStream_Stdout: "out\n"}, read(pb.OutputStream_Stdout))
+	assert.Equal(t, map[pb.OutputStream]string{pb.OutputStream_Stderr: "err\n"}, read(pb.OutputStream_Stderr))
+
+	stream, err := client.Output(ctx, &pb.OutputJobRequest{Id: job.GetId(), Stream: pb.OutputStream(9)})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_OutputTimestamps(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	before := time.Now().Truncate(0)
+	job, err := client.Start(ctx, &pb.JobStartRequest{Command: "echo", Arguments: []string{"hi"}})
+	require.NoError(t, err)
+
+	read := func(timestamps bool) *pb.OutputJobResponse {
+		t.Helper()
+		stream, err := client.Output(ctx, &pb.OutputJobRequest{Id: job.GetId(), Timestamps: timestamps})
+		require.NoError(t, err)
+		resp, err := stream.Recv()
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := read(false)
+	assert.Equal(t, "hi\n", string(resp.GetData()))
+	assert.Nil(t, resp.GetTime())
+
+	resp = read(true)
+	assert.Equal(t, "hi\n", string(resp.GetData()))
+	require.NotNil(t, resp.GetTime())
+	at := resp.GetTime().AsTime()
+	assert.False(t, at.Before(before), "output captured before the job started")
+	assert.False(t, at.After(time.Now()), "output captured in the future")
+}
+
+func TestServer_Input(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	job, err := client.Start(ctx, &pb.JobStartRequest{Command: "cat", Stdin: true})
+	require.NoError(t, err)
+
+	// the ID only has to be in the first message
+	stream, err := client.Input(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.JobInputRequest{Id: job.GetId(), Data: []byte("hel")}))
+	require.NoError(t, stream.Send(&pb.JobInputRequest{Data: []byte("lo\n")}))
+	resp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), resp.GetBytesWritten())
+
+	// closing the stream closed stdin, so cat has exited
+	output, err := client.Output(ctx, &pb.OutputJobRequest{Id: job.GetId()})
+	require.NoError(t, err)
+	var got []byte
+	for {
+		resp, err := output.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, resp.GetData()...)
+	}
+	assert.Equal(t, "hello\n", string(got))
+
+	send := func(reqs ...*pb.JobInputRequest) codes.Code {
+		t.Helper()
+		stream, err := client.Input(ctx)
+		require.NoError(t, err)
+		for _, req := range reqs {
+			require.NoError(t, stream.Send(req))
+		}
+		_, err = stream.CloseAndRecv()
+		return status.Code(err)
+	}
+
+	// stdin is closed now
+	assert.Equal(t, codes.FailedPrecondition, send(&pb.JobInputRequest{Id: job.GetId(), Data: []byte("more")}))
+	assert.Equal(t, codes.InvalidArgument, send())
+	assert.Equal(t, codes.NotFound, send(&pb.JobInputRequest{Id: "nope"}))
+
+	noStdin, err := client.Start(ctx, &pb.JobStartRequest{Command: "sleep", Arguments: []string{"30"}})
+	require.NoError(t, err)
+	defer func() { _, _ = client.Stop(ctx, &pb.JobStopRequest{Id: noStdin.GetId()}) }()
+	assert.Equal(t, codes.FailedPrecondition, send(&pb.JobInputRequest{Id: noStdin.GetId(), Data: []byte("hi")}))
+}
+
+func TestServer_InputKeepOpen(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	job, err := client.Start(ctx, &pb.JobStartRequest{Command: "cat", Stdin: true})
+	require.NoError(t, err)
+
+	send := func(req *pb.JobInputRequest) int64 {
+		t.Helper()
+		stream, err := client.Input(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(req))
+		resp, err := stream.CloseAndRecv()
+		require.NoError(t, err)
+		return resp.GetBytesWritten()
+	}
+
+	assert.Equal(t, int64(4), send(&pb.JobInputRequest{Id: job.GetId(), Data: []byte("one "), KeepOpen: true}))
+	running, err := client.Status(ctx, &pb.JobStatusRequest{Id: job.GetId()})
+	require.NoError(t, err)
+	assert.Equal(t, pb.JobStatus_Running, running.GetStatus())
+
+	assert.Equal(t, int64(4), send(&pb.JobInputRequest{Id: job.GetId(), Data: []byte("two\n")}))
+	output, err := client.Output(ctx, &pb.OutputJobRequest{Id: job.GetId()})
+	require.NoError(t, err)
+	var got []byte
+	for {
+		resp, err := output.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, resp.GetData()...)
+	}
+	assert.Equal(t, "one two\n", string(got))
+}
+
+// recvUntil reads output from an attached stream until it ends with
+// want, returning everything read.
+func recvUntil(t *testing.T, stream pb.Service_AttachClient, want string) string {
+	t.Helper()
+	var got string
+	for !strings.HasSuffix(got, want) {
+		resp, err := stream.Recv()
+		require.NoError(t, err, "got %q so far", got)
+		got += string(resp.GetOutput())
+	}
+	return got
+}
+
+func TestServer_Attach(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	script := `read a; stty size; echo "got $a"; read b; echo "bye $b"; read c`
+	job, err := client.Start(ctx, &pb.JobStartRequest{Command: "sh", Arguments: []string{"-c", script}, Tty: true})
+	require.NoError(t, err)
+	assert.True(t, job.GetTty())
+
+	// the terminal is resized before the input in the same message is
+	// written
+	stream, err := client.Attach(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.AttachRequest{
+		Id:     job.GetId(),
+		Resize: &pb.WindowSize{Rows: 30, Cols: 100},
+		Input:  []byte("hello\n"),
+	}))
+	assert.Equal(t, "hello\r\n30 100\r\ngot hello\r\n", recvUntil(t, stream, "got hello\r\n"))
+
+	// detaching leaves the job running
+	require.NoError(t, stream.CloseSend())
+	_, err = stream.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+	running, err := client.Status(ctx, &pb.JobStatusRequest{Id: job.GetId()})
+	require.NoError(t, err)
+	assert.Equal(t, pb.JobStatus_Running, running.GetStatus())
+
+	stream, err = client.Attach(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.AttachRequest{Id: job.GetId(), Input: []byte("world\n")}))
+	recvUntil(t, stream, "bye world\r\n")
+
+	// only one client can send input at a time
+	other, err := client.Attach(ctx)
+	require.NoError(t, err)
+	require.NoError(t, other.Send(&pb.AttachRequest{Id: job.GetId()}))
+	_, err = other.Recv()
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// the stream ends once the job has ended
+	require.NoError(t, stream.Send(&pb.AttachRequest{Input: []byte("\n")}))
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+	}
+	assert.Equal(t, pb.JobStatus_Finished, waitForJob(t, client, job.GetId()).GetStatus())
+}
+
+func TestServer_AttachErrors(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	attach := func(reqs ...*pb.AttachRequest) codes.Code {
+		t.Helper()
+		stream, err := client.Attach(ctx)
+		require.NoError(t, err)
+		for _, req := range reqs {
+			require.NoError(t, stream.Send(req))
+		}
+		require.NoError(t, stream.CloseSend())
+		_, err = stream.Recv()
+		return status.Code(err)
+	}
+
+	assert.Equal(t, codes.InvalidArgument, attach())
+	assert.Equal(t, codes.NotFound, attach(&pb.AttachRequest{Id: "nope"}))
+
+	noTTY, err := client.Start(ctx, &pb.JobStartRequest{Command: "sleep", Arguments: []string{"30"}, Stdin: true})
+	require.NoError(t, err)
+	defer func() { _, _ = client.Stop(ctx, &pb.JobStopRequest{Id: noTTY.GetId()}) }()
+	assert.Equal(t, codes.FailedPrecondition, attach(&pb.AttachRequest{Id: noTTY.GetId()}))
+
+	tty, err := client.Start(ctx, &pb.JobStartRequest{Command: "sleep", Arguments: []string{"30"}, Tty: true})
+	require.NoError(t, err)
+	defer func() { _, _ = client.Stop(ctx, &pb.JobStopRequest{Id: tty.GetId()}) }()
+	assert.Equal(t, codes.InvalidArgument, attach(&pb.AttachRequest{
+		Id:     tty.GetId(),
+		Resize: &pb.WindowSize{Rows: 1 << 16, Cols: 80},
+	}))
+}
+
+func TestServer_Errors(t *testing.T) {
+	client := newTestClient(t, api.WithLimitCeilings(api.Limits{MaxPids: 20}))
+	ctx := context.Background()
+
+	_, err := client.Status(ctx, &pb.JobStatusRequest{Id: "nope"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.Stop(ctx, &pb.JobStopRequest{Id: "nope"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	stream, err := client.Output(ctx, &pb.OutputJobRequest{Id: "nope"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.Start(ctx, &pb.JobStartRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Start(ctx, &pb.JobStartRequest{Command: "not-a-real-command-workernator"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Start(ctx, &pb.JobStartRequest{
+		Command: "true",
+		Limits:  &pb.ResourceLimits{MaxPids: 100},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.Start(ctx, &pb.JobStartRequest{
+		Command: "true",
+		Limits:  &pb.ResourceLimits{CpuQuota: &durationpb.Duration{Seconds: 1, Nanos: -1}},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
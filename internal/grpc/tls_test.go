@@ -0,0 +1,221 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/seanhagen/workernator/internal/pb"
+	"github.com/seanhagen/workernator/internal/tlsconfig"
+	"github.com/seanhagen/workernator/library/api"
+)
+
+// the certificates in testdata have expired, so the tests pretend it's
+// still the time they were valid
+func testdataTime() time.Time {
+	return time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func serverTLS() tlsconfig.Config {
+	return tlsconfig.Config{
+		RootCert: "testdata/ca.pem",
+		Cert:     "testdata/server.pem",
+		Key:      "testdata/cakey.key",
+		Time:     testdataTime,
+	}
+}
+
+func clientTLS() tlsconfig.Config {
+	return tlsconfig.Config{
+		RootCert: "testdata/ca.pem",
+		Cert:     "testdata/client.pem",
+		Key:      "testdata/cakey.key",
+		Time:     testdataTime,
+	}
+}
+
+// signedCert creates a certificate with the given subject, signed by
+// the testdata CA, and returns the paths to the certificate and key.
+func signedCert(t *testing.T, subject pkix.Name, usage x509.ExtKeyUsage) (string, string) {
+	t.Helper()
+
+	caPEM, err := os.ReadFile("testdata/ca.pem")
+	require.NoError(t, err)
+	block, _ := pem.Decode(caPEM)
+	caCert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	caKeyPEM, err := os.ReadFile("testdata/cakey.key")
+	require.NoError(t, err)
+	block, _ = pem.Decode(caKeyPEM)
+	caKey, err := x509.ParseECPrivateKey(block.Bytes)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      subject,
+		NotBefore:    testdataTime().Add(-time.Hour),
+		NotAfter:     testdataTime().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certPath, keyPath
+}
+
+// startTLSServer starts a server using the given TLS config on a random
+// local port, returning the address it's listening on.
+func startTLSServer(t *testing.T, conf tlsconfig.Config, opts ...grpc.ServerOption) string {
+	t.Helper()
+
+	creds, err := tlsconfig.ServerCredentials(conf)
+	require.NoError(t, err)
+
+	manager, err := api.NewManager(api.WithWorkDir(t.TempDir()))
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer(append(opts, grpc.Creds(creds))...)
+	pb.RegisterServiceServer(srv, NewServer(manager))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String()
+}
+
+// callServer makes a request to the server at addr using the given TLS
+// config, returning the status code of the response.
+func callServer(t *testing.T, addr string, conf tlsconfig.Config) codes.Code {
+	t.Helper()
+
+	creds, err := tlsconfig.ClientCredentials(conf)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(creds))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// no job has this ID, so NotFound means the request got through
+	_, err = pb.NewServiceClient(conn).Status(ctx, &pb.JobStatusRequest{Id: "nope"})
+	return status.Code(err)
+}
+
+func TestTLS_Valid(t *testing.T) {
+	addr := startTLSServer(t, serverTLS())
+	assert.Equal(t, codes.NotFound, callServer(t, addr, clientTLS()))
+}
+
+func TestTLS_Expired(t *testing.T) {
+	// without pretending it's 2022, the testdata certificates have expired
+	conf := serverTLS()
+	conf.Time = nil
+	addr := startTLSServer(t, conf)
+
+	assert.Equal(t, codes.Unavailable, callServer(t, addr, clientTLS()))
+}
+
+func TestTLS_InvalidClientCertificate(t *testing.T) {
+	addr := startTLSServer(t, serverTLS())
+
+	tests := map[string]pkix.Name{
+		"wrong organization": {Organization: []string{"Evil Corp"}, OrganizationalUnit: []string{"workernator"}, CommonName: "client"},
+		"wrong unit":         {Organization: []string{"Teleport"}, OrganizationalUnit: []string{"other"}, CommonName: "client"},
+		"server common name": {Organization: []string{"Teleport"}, OrganizationalUnit: []string{"workernator"}, CommonName: "server"},
+		"no subject":         {},
+	}
+
+	for name, subject := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf := clientTLS()
+			conf.Cert, conf.Key = signedCert(t, subject, x509.ExtKeyUsageClientAuth)
+			assert.Equal(t, codes.Unavailable, callServer(t, addr, conf))
+		})
+	}
+
+	// the server certificate can't be used by a client
+	conf := clientTLS()
+	conf.Cert = "testdata/server.pem"
+	assert.Equal(t, codes.Unavailable, callServer(t, addr, conf))
+}
+
+func TestTLS_InvalidServerCertificate(t *testing.T) {
+	// a client certificate, or a certificate with the wrong subject,
+	// can't be used to run a server
+	tests := map[string]pkix.Name{
+		"client common name": {Organization: []string{"Teleport"}, OrganizationalUnit: []string{"workernator"}, CommonName: "client"},
+		"wrong organization": {Organization: []string{"Evil Corp"}, OrganizationalUnit: []string{"workernator"}, CommonName: "server"},
+	}
+
+	for name, subject := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf := serverTLS()
+			conf.Cert, conf.Key = signedCert(t, subject, x509.ExtKeyUsageServerAuth)
+			addr := startTLSServer(t, conf)
+			assert.Equal(t, codes.Unavailable, callServer(t, addr, clientTLS()))
+		})
+	}
+
+	// sanity check that a certificate generated the same way with the
+	// right subject works
+	conf := serverTLS()
+	conf.Cert, conf.Key = signedCert(t, pkix.Name{
+		Organization:       []string{"Teleport"},
+		OrganizationalUnit: []string{"workernator"},
+		CommonName:         "server",
+	}, x509.ExtKeyUsageServerAuth)
+	addr := startTLSServer(t, conf)
+	assert.Equal(t, codes.NotFound, callServer(t, addr, clientTLS()))
+}
+
+func TestTLS_MinimumVersion(t *testing.T) {
+	addr := startTLSServer(t, serverTLS())
+
+	cert, err := tls.LoadX509KeyPair("testdata/client.pem", "testdata/cakey.key")
+	require.NoError(t, err)
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{
+		MaxVersion:         tls.VersionTLS12,
+		Certificates:       []tls.Certificate{cert},
+		InsecureSkipVerify: true, //nolint:gosec // only checking the TLS version
+	})
+	if err == nil {
+		_ = conn.Close()
+	}
+	assert.Error(t, err, "server should refuse TLS v1.2")
+}
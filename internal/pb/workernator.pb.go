@@ -9,6 +9,7 @@ package pb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -38,6 +39,9 @@ const (
 	JobStatus_Finished JobStatus = 3
 	// Stopped means the job was stopped by a user before it finished.
 	JobStatus_Stopped JobStatus = 4
+	// TimedOut means the job was stopped because it ran for longer than
+	// its max runtime.
+	JobStatus_TimedOut JobStatus = 5
 )
 
 // Enum value maps for JobStatus.
@@ -48,6 +52,7 @@ var (
 		2: "Failed",
 		3: "Finished",
 		4: "Stopped",
+		5: "TimedOut",
 	}
 	JobStatus_value = map[string]int32{
 		"Unknown":  0,
@@ -55,6 +60,7 @@ var (
 		"Failed":   2,
 		"Finished": 3,
 		"Stopped":  4,
+		"TimedOut": 5,
 	}
 )
 
@@ -85,6 +91,361 @@ func (JobStatus) EnumDescriptor() ([]byte, []int) {
 	return file_workernator_proto_rawDescGZIP(), []int{0}
 }
 
+// TerminationReason is why a job ended.
+type TerminationReason int32
+
+const (
+	// NotTerminated means the job is still running.
+	TerminationReason_NotTerminated TerminationReason = 0
+	// Exited means the job exited on its own, whether or not it was
+	// successful.
+	TerminationReason_Exited TerminationReason = 1
+	// Signaled means the job was killed by a signal that didn't come from
+	// the service.
+	TerminationReason_Signaled TerminationReason = 2
+	// UserStopped means the job was stopped by a user.
+	TerminationReason_UserStopped TerminationReason = 3
+	// OOMKilled means the job failed after a process in it was killed for
+	// using more memory than its limit allowed.
+	TerminationReason_OOMKilled TerminationReason = 4
+	// PidsExhausted means the job failed after it tried to start more
+	// processes than its limit allowed.
+	TerminationReason_PidsExhausted TerminationReason = 5
+	// Timeout means the job was stopped because it ran for longer than its
+	// max runtime.
+	TerminationReason_Timeout TerminationReason = 6
+)
+
+// Enum value maps for TerminationReason.
+var (
+	TerminationReason_name = map[int32]string{
+		0: "NotTerminated",
+		1: "Exited",
+		2: "Signaled",
+		3: "UserStopped",
+		4: "OOMKilled",
+		5: "PidsExhausted",
+		6: "Timeout",
+	}
+	TerminationReason_value = map[string]int32{
+		"NotTerminated": 0,
+		"Exited":        1,
+		"Signaled":      2,
+		"UserStopped":   3,
+		"OOMKilled":     4,
+		"PidsExhausted": 5,
+		"Timeout":       6,
+	}
+)
+
+func (x TerminationReason) Enum() *TerminationReason {
+	p := new(TerminationReason)
+	*p = x
+	return p
+}
+
+func (x TerminationReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TerminationReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_workernator_proto_enumTypes[1].Descriptor()
+}
+
+func (TerminationReason) Type() protoreflect.EnumType {
+	return &file_workernator_proto_enumTypes[1]
+}
+
+func (x TerminationReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TerminationReason.Descriptor instead.
+func (TerminationReason) EnumDescriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{1}
+}
+
+// JobEventType is what happened to a job.
+type JobEventType int32
+
+const (
+	// UnknownEvent is never sent.
+	JobEventType_UnknownEvent JobEventType = 0
+	// JobCreated is sent once a job has been added, just before
+	// JobStarted. The job is as it was just before its command was
+	// started, so has the Unknown status. Jobs that couldn't be started
+	// are never added, so have no events sent.
+	JobEventType_JobCreated JobEventType = 1
+	// JobStarted is sent once the job command is running.
+	JobEventType_JobStarted JobEventType = 2
+	// JobStopped is sent when a job ends after being stopped by a user, or
+	// by the service shutting down.
+	JobEventType_JobStopped JobEventType = 3
+	// JobTimedOut is sent when a job ends after running for longer than
+	// its max runtime.
+	JobEventType_JobTimedOut JobEventType = 4
+	// JobFinished is sent when a job ends successfully.
+	JobEventType_JobFinished JobEventType = 5
+	// JobFailed is sent when a job ends unsuccessfully.
+	JobEventType_JobFailed JobEventType = 6
+)
+
+// Enum value maps for JobEventType.
+var (
+	JobEventType_name = map[int32]string{
+		0: "UnknownEvent",
+		1: "JobCreated",
+		2: "JobStarted",
+		3: "JobStopped",
+		4: "JobTimedOut",
+		5: "JobFinished",
+		6: "JobFailed",
+	}
+	JobEventType_value = map[string]int32{
+		"UnknownEvent": 0,
+		"JobCreated":   1,
+		"JobStarted":   2,
+		"JobStopped":   3,
+		"JobTimedOut":  4,
+		"JobFinished":  5,
+		"JobFailed":    6,
+	}
+)
+
+func (x JobEventType) Enum() *JobEventType {
+	p := new(JobEventType)
+	*p = x
+	return p
+}
+
+func (x JobEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_workernator_proto_enumTypes[2].Descriptor()
+}
+
+func (JobEventType) Type() protoreflect.EnumType {
+	return &file_workernator_proto_enumTypes[2]
+}
+
+func (x JobEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobEventType.Descriptor instead.
+func (JobEventType) EnumDescriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{2}
+}
+
+// OutputStream is which of the output streams of a job some output came
+// from.
+type OutputStream int32
+
+const (
+	// Combined is both stdout and stderr, in the order they were captured.
+	// Each stream keeps its own order, but the two are captured separately,
+	// so lines written close together on different streams may be swapped.
+	// It's only used when requesting output.
+	OutputStream_Combined OutputStream = 0
+	// Stdout is the output the job wrote to stdout.
+	OutputStream_Stdout OutputStream = 1
+	// Stderr is the output the job wrote to stderr.
+	OutputStream_Stderr OutputStream = 2
+)
+
+// Enum value maps for OutputStream.
+var (
+	OutputStream_name = map[int32]string{
+		0: "Combined",
+		1: "Stdout",
+		2: "Stderr",
+	}
+	OutputStream_value = map[string]int32{
+		"Combined": 0,
+		"Stdout":   1,
+		"Stderr":   2,
+	}
+)
+
+func (x OutputStream) Enum() *OutputStream {
+	p := new(OutputStream)
+	*p = x
+	return p
+}
+
+func (x OutputStream) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OutputStream) Descriptor() protoreflect.EnumDescriptor {
+	return file_workernator_proto_enumTypes[3].Descriptor()
+}
+
+func (OutputStream) Type() protoreflect.EnumType {
+	return &file_workernator_proto_enumTypes[3]
+}
+
+func (x OutputStream) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OutputStream.Descriptor instead.
+func (OutputStream) EnumDescriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{3}
+}
+
+// IOLimit limits the read & write bandwidth of a job for a single block
+// device.
+type IOLimit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// device is the "major:minor" number of the block device, ie "8:0".
+	Device           string `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`
+	ReadBytesPerSec  int64  `protobuf:"varint,2,opt,name=read_bytes_per_sec,json=readBytesPerSec,proto3" json:"read_bytes_per_sec,omitempty"`
+	WriteBytesPerSec int64  `protobuf:"varint,3,opt,name=write_bytes_per_sec,json=writeBytesPerSec,proto3" json:"write_bytes_per_sec,omitempty"`
+}
+
+func (x *IOLimit) Reset() {
+	*x = IOLimit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_workernator_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *IOLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IOLimit) ProtoMessage() {}
+
+func (x *IOLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IOLimit.ProtoReflect.Descriptor instead.
+func (*IOLimit) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *IOLimit) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *IOLimit) GetReadBytesPerSec() int64 {
+	if x != nil {
+		return x.ReadBytesPerSec
+	}
+	return 0
+}
+
+func (x *IOLimit) GetWriteBytesPerSec() int64 {
+	if x != nil {
+		return x.WriteBytesPerSec
+	}
+	return 0
+}
+
+// ResourceLimits are the limits on the resources a job is allowed to
+// use. Any fields left unset use the defaults configured in the
+// service, and no limit can be higher than the maximum configured in
+// the service.
+type ResourceLimits struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MaxPids int64 `protobuf:"varint,1,opt,name=max_pids,json=maxPids,proto3" json:"max_pids,omitempty"`
+	// cpu_quota is how much CPU time the job can use every cpu_period.
+	CpuQuota    *durationpb.Duration `protobuf:"bytes,2,opt,name=cpu_quota,json=cpuQuota,proto3" json:"cpu_quota,omitempty"`
+	CpuPeriod   *durationpb.Duration `protobuf:"bytes,3,opt,name=cpu_period,json=cpuPeriod,proto3" json:"cpu_period,omitempty"`
+	MemoryBytes int64                `protobuf:"varint,4,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`
+	Io          []*IOLimit           `protobuf:"bytes,5,rep,name=io,proto3" json:"io,omitempty"`
+}
+
+func (x *ResourceLimits) Reset() {
+	*x = ResourceLimits{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_workernator_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResourceLimits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceLimits) ProtoMessage() {}
+
+func (x *ResourceLimits) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceLimits.ProtoReflect.Descriptor instead.
+func (*ResourceLimits) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ResourceLimits) GetMaxPids() int64 {
+	if x != nil {
+		return x.MaxPids
+	}
+	return 0
+}
+
+func (x *ResourceLimits) GetCpuQuota() *durationpb.Duration {
+	if x != nil {
+		return x.CpuQuota
+	}
+	return nil
+}
+
+func (x *ResourceLimits) GetCpuPeriod() *durationpb.Duration {
+	if x != nil {
+		return x.CpuPeriod
+	}
+	return nil
+}
+
+func (x *ResourceLimits) GetMemoryBytes() int64 {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return 0
+}
+
+func (x *ResourceLimits) GetIo() []*IOLimit {
+	if x != nil {
+		return x.Io
+	}
+	return nil
+}
+
 // Job contains information about a job that was created at some point while the
 // service is running.
 type Job struct {
@@ -92,19 +453,40 @@ type Job struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Status    JobStatus              `protobuf:"varint,10,opt,name=status,proto3,enum=seanhagen.pb.JobStatus" json:"status,omitempty"`
-	Command   string                 `protobuf:"bytes,11,opt,name=command,proto3" json:"command,omitempty"`
-	Args      []string               `protobuf:"bytes,12,rep,name=args,proto3" json:"args,omitempty"`
-	ErrorMsg  string                 `protobuf:"bytes,13,opt,name=error_msg,json=errorMsg,proto3" json:"error_msg,omitempty"`
-	StartedAt *timestamppb.Timestamp `protobuf:"bytes,21,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
-	EndedAt   *timestamppb.Timestamp `protobuf:"bytes,22,opt,name=ended_at,json=endedAt,proto3" json:"ended_at,omitempty"`
+	Id       string    `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status   JobStatus `protobuf:"varint,10,opt,name=status,proto3,enum=seanhagen.pb.JobStatus" json:"status,omitempty"`
+	Command  string    `protobuf:"bytes,11,opt,name=command,proto3" json:"command,omitempty"`
+	Args     []string  `protobuf:"bytes,12,rep,name=args,proto3" json:"args,omitempty"`
+	ErrorMsg string    `protobuf:"bytes,13,opt,name=error_msg,json=errorMsg,proto3" json:"error_msg,omitempty"`
+	// limits are the resource limits the job is running with.
+	Limits *ResourceLimits `protobuf:"bytes,14,opt,name=limits,proto3" json:"limits,omitempty"`
+	// owner is the user that started the job.
+	Owner string `protobuf:"bytes,15,opt,name=owner,proto3" json:"owner,omitempty"`
+	// signal is the name of the signal that ended the job, ie 'SIGKILL'.
+	// It's empty if the job is running, or exited on its own.
+	Signal string `protobuf:"bytes,16,opt,name=signal,proto3" json:"signal,omitempty"`
+	// termination_reason is why the job ended.
+	TerminationReason TerminationReason `protobuf:"varint,19,opt,name=termination_reason,json=terminationReason,proto3,enum=seanhagen.pb.TerminationReason" json:"termination_reason,omitempty"`
+	// exit_code is the exit code of the job once it has ended, or -1 if
+	// it was ended by a signal.
+	ExitCode int32 `protobuf:"varint,17,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"`
+	// usage is the resources the job used, which is only set once the job
+	// has ended.
+	Usage *ResourceUsage `protobuf:"bytes,18,opt,name=usage,proto3" json:"usage,omitempty"`
+	// max_runtime is how long the job is allowed to run. It's unset if the
+	// job can run forever.
+	MaxRuntime *durationpb.Duration   `protobuf:"bytes,20,opt,name=max_runtime,json=maxRuntime,proto3" json:"max_runtime,omitempty"`
+	StartedAt  *timestamppb.Timestamp `protobuf:"bytes,21,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	EndedAt    *timestamppb.Timestamp `protobuf:"bytes,22,opt,name=ended_at,json=endedAt,proto3" json:"ended_at,omitempty"`
+	// tty is set if the job was started with a terminal, so it can be
+	// used with 'Attach'.
+	Tty bool `protobuf:"varint,23,opt,name=tty,proto3" json:"tty,omitempty"`
 }
 
 func (x *Job) Reset() {
 	*x = Job{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_workernator_proto_msgTypes[0]
+		mi := &file_workernator_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -117,7 +499,7 @@ func (x *Job) String() string {
 func (*Job) ProtoMessage() {}
 
 func (x *Job) ProtoReflect() protoreflect.Message {
-	mi := &file_workernator_proto_msgTypes[0]
+	mi := &file_workernator_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -130,7 +512,7 @@ func (x *Job) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Job.ProtoReflect.Descriptor instead.
 func (*Job) Descriptor() ([]byte, []int) {
-	return file_workernator_proto_rawDescGZIP(), []int{0}
+	return file_workernator_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *Job) GetId() string {
@@ -168,47 +550,802 @@ func (x *Job) GetErrorMsg() string {
 	return ""
 }
 
+func (x *Job) GetLimits() *ResourceLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+func (x *Job) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *Job) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *Job) GetTerminationReason() TerminationReason {
+	if x != nil {
+		return x.TerminationReason
+	}
+	return TerminationReason_NotTerminated
+}
+
+func (x *Job) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *Job) GetUsage() *ResourceUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+func (x *Job) GetMaxRuntime() *durationpb.Duration {
+	if x != nil {
+		return x.MaxRuntime
+	}
+	return nil
+}
+
 func (x *Job) GetStartedAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.StartedAt
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Job) GetEndedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndedAt
+	}
+	return nil
+}
+
+func (x *Job) GetTty() bool {
+	if x != nil {
+		return x.Tty
+	}
+	return false
+}
+
+// ResourceUsage is how much of the system's resources a job used.
+type ResourceUsage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// user_cpu is the CPU time spent running the job's code.
+	UserCpu *durationpb.Duration `protobuf:"bytes,1,opt,name=user_cpu,json=userCpu,proto3" json:"user_cpu,omitempty"`
+	// system_cpu is the CPU time the kernel spent on behalf of the job.
+	SystemCpu *durationpb.Duration `protobuf:"bytes,2,opt,name=system_cpu,json=systemCpu,proto3" json:"system_cpu,omitempty"`
+	// peak_memory_bytes is the most memory the job used at once.
+	PeakMemoryBytes int64 `protobuf:"varint,3,opt,name=peak_memory_bytes,json=peakMemoryBytes,proto3" json:"peak_memory_bytes,omitempty"`
+	// io_read_bytes and io_write_bytes are how many bytes the job read
+	// from and wrote to block devices.
+	IoReadBytes  int64 `protobuf:"varint,4,opt,name=io_read_bytes,json=ioReadBytes,proto3" json:"io_read_bytes,omitempty"`
+	IoWriteBytes int64 `protobuf:"varint,5,opt,name=io_write_bytes,json=ioWriteBytes,proto3" json:"io_write_bytes,omitempty"`
+}
+
+func (x *ResourceUsage) Reset() {
+	*x = ResourceUsage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_workernator_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResourceUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceUsage) ProtoMessage() {}
+
+func (x *ResourceUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceUsage.ProtoReflect.Descriptor instead.
+func (*ResourceUsage) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ResourceUsage) GetUserCpu() *durationpb.Duration {
+	if x != nil {
+		return x.UserCpu
+	}
+	return nil
+}
+
+func (x *ResourceUsage) GetSystemCpu() *durationpb.Duration {
+	if x != nil {
+		return x.SystemCpu
+	}
+	return nil
+}
+
+func (x *ResourceUsage) GetPeakMemoryBytes() int64 {
+	if x != nil {
+		return x.PeakMemoryBytes
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetIoReadBytes() int64 {
+	if x != nil {
+		return x.IoReadBytes
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetIoWriteBytes() int64 {
+	if x != nil {
+		return x.IoWriteBytes
+	}
+	return 0
+}
+
+// JobStartRequest is sent to request a job be started in the service.
+type JobStartRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Command   string   `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	Arguments []string `protobuf:"bytes,2,rep,name=arguments,proto3" json:"arguments,omitempty"`
+	// limits optionally sets the resource limits for the job.
+	Limits *ResourceLimits `protobuf:"bytes,3,opt,name=limits,proto3" json:"limits,omitempty"`
+	// max_runtime optionally sets how long the job is allowed to run. Once
+	// it has run for longer it's sent SIGTERM, and is killed if it's still
+	// running after a grace period. If it's unset the maximum configured in
+	// the service is used, and it can't be higher than that maximum.
+	MaxRuntime *durationpb.Duration `protobuf:"bytes,4,opt,name=max_runtime,json=maxRuntime,proto3" json:"max_runtime,omitempty"`
+	// stdin, if set, keeps the stdin of the job open so that input can be
+	// sent to it using 'Input'. Otherwise the job reads EOF from stdin
+	// straight away.
+	Stdin bool `protobuf:"varint,5,opt,name=stdin,proto3" json:"stdin,omitempty"`
+	// tty, if set, runs the job with a pseudo-terminal as its stdin, stdout,
+	// and stderr, so that it can be used interactively with 'Attach'.
+	// Everything the job writes is sent as Stdout. Input can also be sent
+	// using 'Input', but the terminal stays open once the input ends.
+	Tty bool `protobuf:"varint,6,opt,name=tty,proto3" json:"tty,omitempty"`
+}
+
+func (x *JobStartRequest) Reset() {
+	*x = JobStartRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_workernator_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JobStartRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobStartRequest) ProtoMessage() {}
+
+func (x *JobStartRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobStartRequest.ProtoReflect.Descriptor instead.
+func (*JobStartRequest) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *JobStartRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *JobStartRequest) GetArguments() []string {
+	if x != nil {
+		return x.Arguments
+	}
+	return nil
+}
+
+func (x *JobStartRequest) GetLimits() *ResourceLimits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+func (x *JobStartRequest) GetMaxRuntime() *durationpb.Duration {
+	if x != nil {
+		return x.MaxRuntime
+	}
+	return nil
+}
+
+func (x *JobStartRequest) GetStdin() bool {
+	if x != nil {
+		return x.Stdin
+	}
+	return false
+}
+
+func (x *JobStartRequest) GetTty() bool {
+	if x != nil {
+		return x.Tty
+	}
+	return false
+}
+
+// JobStopRequest is sent to 'Stop' to request a job be stopped.
+type JobStopRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// grace_period, if set, gives the job a chance to exit cleanly. The
+	// job is sent SIGTERM, and is only killed with SIGKILL if it's still
+	// running once the grace period is over. Without it the job is killed
+	// immediately.
+	GracePeriod *durationpb.Duration `protobuf:"bytes,2,opt,name=grace_period,json=gracePeriod,proto3" json:"grace_period,omitempty"`
+}
+
+func (x *JobStopRequest) Reset() {
+	*x = JobStopRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_workernator_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JobStopRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobStopRequest) ProtoMessage() {}
+
+func (x *JobStopRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobStopRequest.ProtoReflect.Descriptor instead.
+func (*JobStopRequest) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *JobStopRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *JobStopRequest) GetGracePeriod() *durationpb.Duration {
+	if x != nil {
+		return x.GracePeriod
+	}
+	return nil
+}
+
+// JobStatusRequest is used to request the status of a job.
+type JobStatusRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *JobStatusRequest) Reset() {
+	*x = JobStatusRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_workernator_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JobStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobStatusRequest) ProtoMessage() {}
+
+func (x *JobStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobStatusRequest.ProtoReflect.Descriptor instead.
+func (*JobStatusRequest) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *JobStatusRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// JobStatusResponse returns the Job, if found.
+type JobStatusResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Job *Job `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+}
+
+func (x *JobStatusResponse) Reset() {
+	*x = JobStatusResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_workernator_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JobStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobStatusResponse) ProtoMessage() {}
+
+func (x *JobStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobStatusResponse.ProtoReflect.Descriptor instead.
+func (*JobStatusResponse) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *JobStatusResponse) GetJob() *Job {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+// JobListRequest is used to find jobs. A job has to match every filter
+// that's set to be returned.
+type JobListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// statuses matches jobs in any of the given states.
+	Statuses []JobStatus `protobuf:"varint,1,rep,packed,name=statuses,proto3,enum=seanhagen.pb.JobStatus" json:"statuses,omitempty"`
+	// owner matches jobs started by the given user.
+	Owner string `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	// command matches jobs running exactly the given command.
+	Command string `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`
+	// started_after and started_before match jobs started at or after,
+	// and before, the given times.
+	StartedAfter  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=started_after,json=startedAfter,proto3" json:"started_after,omitempty"`
+	StartedBefore *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=started_before,json=startedBefore,proto3" json:"started_before,omitempty"`
+	// page_size is the maximum number of jobs to return. The service uses
+	// a default if it's not set, and caps how large it can be.
+	PageSize int32 `protobuf:"varint,6,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// page_token is the next_page_token from the previous response, to
+	// get the next page of jobs.
+	PageToken string `protobuf:"bytes,7,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+}
+
+func (x *JobListRequest) Reset() {
+	*x = JobListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_workernator_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JobListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobListRequest) ProtoMessage() {}
+
+func (x *JobListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobListRequest.ProtoReflect.Descriptor instead.
+func (*JobListRequest) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *JobListRequest) GetStatuses() []JobStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *JobListRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *JobListRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *JobListRequest) GetStartedAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAfter
+	}
+	return nil
+}
+
+func (x *JobListRequest) GetStartedBefore() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedBefore
+	}
+	return nil
+}
+
+func (x *JobListRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *JobListRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// JobListResponse contains a page of jobs, in the order they were
+// started.
+type JobListResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Jobs []*Job `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	// next_page_token is sent in the next request to get the next page of
+	// jobs. It's empty if there are no more jobs.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+}
+
+func (x *JobListResponse) Reset() {
+	*x = JobListResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_workernator_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JobListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobListResponse) ProtoMessage() {}
+
+func (x *JobListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobListResponse.ProtoReflect.Descriptor instead.
+func (*JobListResponse) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *JobListResponse) GetJobs() []*Job {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+func (x *JobListResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// JobWatchRequest is used to choose which jobs 'Watch' sends events
+// for. Events for every job are sent if no filters are set.
+type JobWatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// ids only sends events for the jobs with these IDs.
+	Ids []string `protobuf:"bytes,1,rep,name=ids,proto3" json:"ids,omitempty"`
+	// owner only sends events for jobs started by this user.
+	Owner string `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+}
+
+func (x *JobWatchRequest) Reset() {
+	*x = JobWatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_workernator_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JobWatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobWatchRequest) ProtoMessage() {}
+
+func (x *JobWatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobWatchRequest.ProtoReflect.Descriptor instead.
+func (*JobWatchRequest) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *JobWatchRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *JobWatchRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+// JobEvent is something that happened to a job.
+type JobEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type JobEventType `protobuf:"varint,1,opt,name=type,proto3,enum=seanhagen.pb.JobEventType" json:"type,omitempty"`
+	// job is a snapshot of the job taken just after the event happened.
+	Job  *Job                   `protobuf:"bytes,2,opt,name=job,proto3" json:"job,omitempty"`
+	Time *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
+}
+
+func (x *JobEvent) Reset() {
+	*x = JobEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_workernator_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JobEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobEvent) ProtoMessage() {}
+
+func (x *JobEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobEvent.ProtoReflect.Descriptor instead.
+func (*JobEvent) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *JobEvent) GetType() JobEventType {
+	if x != nil {
+		return x.Type
+	}
+	return JobEventType_UnknownEvent
+}
+
+func (x *JobEvent) GetJob() *Job {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+func (x *JobEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+// JobInputRequest carries input for the stdin of a job.
+type JobInputRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// id is the job to send input to. It only has to be set in the first
+	// message, and is ignored in the rest.
+	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// keep_open, if set, ends the input without closing stdin, so that more
+	// can be sent using another call. The data in the same message is
+	// still written.
+	KeepOpen bool `protobuf:"varint,3,opt,name=keep_open,json=keepOpen,proto3" json:"keep_open,omitempty"`
+}
+
+func (x *JobInputRequest) Reset() {
+	*x = JobInputRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_workernator_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JobInputRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobInputRequest) ProtoMessage() {}
+
+func (x *JobInputRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobInputRequest.ProtoReflect.Descriptor instead.
+func (*JobInputRequest) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *JobInputRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *JobInputRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
 	}
 	return nil
 }
 
-func (x *Job) GetEndedAt() *timestamppb.Timestamp {
+func (x *JobInputRequest) GetKeepOpen() bool {
 	if x != nil {
-		return x.EndedAt
+		return x.KeepOpen
 	}
-	return nil
+	return false
 }
 
-// JobStartRequest is sent to request a job be started in the service.
-type JobStartRequest struct {
+// JobInputResponse is sent once all of the input has been written to the
+// stdin of the job.
+type JobInputResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Command   string   `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
-	Arguments []string `protobuf:"bytes,2,rep,name=arguments,proto3" json:"arguments,omitempty"`
+	BytesWritten int64 `protobuf:"varint,1,opt,name=bytes_written,json=bytesWritten,proto3" json:"bytes_written,omitempty"`
 }
 
-func (x *JobStartRequest) Reset() {
-	*x = JobStartRequest{}
+func (x *JobInputResponse) Reset() {
+	*x = JobInputResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_workernator_proto_msgTypes[1]
+		mi := &file_workernator_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *JobStartRequest) String() string {
+func (x *JobInputResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JobStartRequest) ProtoMessage() {}
+func (*JobInputResponse) ProtoMessage() {}
 
-func (x *JobStartRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workernator_proto_msgTypes[1]
+func (x *JobInputResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -219,52 +1356,45 @@ func (x *JobStartRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JobStartRequest.ProtoReflect.Descriptor instead.
-func (*JobStartRequest) Descriptor() ([]byte, []int) {
-	return file_workernator_proto_rawDescGZIP(), []int{1}
-}
-
-func (x *JobStartRequest) GetCommand() string {
-	if x != nil {
-		return x.Command
-	}
-	return ""
+// Deprecated: Use JobInputResponse.ProtoReflect.Descriptor instead.
+func (*JobInputResponse) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *JobStartRequest) GetArguments() []string {
+func (x *JobInputResponse) GetBytesWritten() int64 {
 	if x != nil {
-		return x.Arguments
+		return x.BytesWritten
 	}
-	return nil
+	return 0
 }
 
-// JobStopRequest is sent to 'Stop' to request a job be stopped
-// immediately.
-type JobStopRequest struct {
+// WindowSize is the size of a terminal, in characters.
+type WindowSize struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Rows uint32 `protobuf:"varint,1,opt,name=rows,proto3" json:"rows,omitempty"`
+	Cols uint32 `protobuf:"varint,2,opt,name=cols,proto3" json:"cols,omitempty"`
 }
 
-func (x *JobStopRequest) Reset() {
-	*x = JobStopRequest{}
+func (x *WindowSize) Reset() {
+	*x = WindowSize{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_workernator_proto_msgTypes[2]
+		mi := &file_workernator_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *JobStopRequest) String() string {
+func (x *WindowSize) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JobStopRequest) ProtoMessage() {}
+func (*WindowSize) ProtoMessage() {}
 
-func (x *JobStopRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workernator_proto_msgTypes[2]
+func (x *WindowSize) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -275,44 +1405,58 @@ func (x *JobStopRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JobStopRequest.ProtoReflect.Descriptor instead.
-func (*JobStopRequest) Descriptor() ([]byte, []int) {
-	return file_workernator_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use WindowSize.ProtoReflect.Descriptor instead.
+func (*WindowSize) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *JobStopRequest) GetId() string {
+func (x *WindowSize) GetRows() uint32 {
 	if x != nil {
-		return x.Id
+		return x.Rows
 	}
-	return ""
+	return 0
 }
 
-// JobStatusRequest is used to request the status of a job.
-type JobStatusRequest struct {
+func (x *WindowSize) GetCols() uint32 {
+	if x != nil {
+		return x.Cols
+	}
+	return 0
+}
+
+// AttachRequest carries input for the terminal of a job, or a change to
+// its window size.
+type AttachRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// id is the job to attach to. It only has to be set in the first
+	// message, and is ignored in the rest.
+	Id    string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Input []byte `protobuf:"bytes,2,opt,name=input,proto3" json:"input,omitempty"`
+	// resize, if set, changes the window size of the terminal before the
+	// input in the same message is written.
+	Resize *WindowSize `protobuf:"bytes,3,opt,name=resize,proto3" json:"resize,omitempty"`
 }
 
-func (x *JobStatusRequest) Reset() {
-	*x = JobStatusRequest{}
+func (x *AttachRequest) Reset() {
+	*x = AttachRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_workernator_proto_msgTypes[3]
+		mi := &file_workernator_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *JobStatusRequest) String() string {
+func (x *AttachRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JobStatusRequest) ProtoMessage() {}
+func (*AttachRequest) ProtoMessage() {}
 
-func (x *JobStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workernator_proto_msgTypes[3]
+func (x *AttachRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -323,44 +1467,58 @@ func (x *JobStatusRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JobStatusRequest.ProtoReflect.Descriptor instead.
-func (*JobStatusRequest) Descriptor() ([]byte, []int) {
-	return file_workernator_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use AttachRequest.ProtoReflect.Descriptor instead.
+func (*AttachRequest) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *JobStatusRequest) GetId() string {
+func (x *AttachRequest) GetId() string {
 	if x != nil {
 		return x.Id
 	}
 	return ""
 }
 
-// JobStatusResponse returns the Job, if found.
-type JobStatusResponse struct {
+func (x *AttachRequest) GetInput() []byte {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+func (x *AttachRequest) GetResize() *WindowSize {
+	if x != nil {
+		return x.Resize
+	}
+	return nil
+}
+
+// AttachResponse carries output written to the terminal of a job.
+type AttachResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Job *Job `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	Output []byte `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
 }
 
-func (x *JobStatusResponse) Reset() {
-	*x = JobStatusResponse{}
+func (x *AttachResponse) Reset() {
+	*x = AttachResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_workernator_proto_msgTypes[4]
+		mi := &file_workernator_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *JobStatusResponse) String() string {
+func (x *AttachResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*JobStatusResponse) ProtoMessage() {}
+func (*AttachResponse) ProtoMessage() {}
 
-func (x *JobStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_workernator_proto_msgTypes[4]
+func (x *AttachResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_workernator_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -371,14 +1529,14 @@ func (x *JobStatusResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use JobStatusResponse.ProtoReflect.Descriptor instead.
-func (*JobStatusResponse) Descriptor() ([]byte, []int) {
-	return file_workernator_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use AttachResponse.ProtoReflect.Descriptor instead.
+func (*AttachResponse) Descriptor() ([]byte, []int) {
+	return file_workernator_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *JobStatusResponse) GetJob() *Job {
+func (x *AttachResponse) GetOutput() []byte {
 	if x != nil {
-		return x.Job
+		return x.Output
 	}
 	return nil
 }
@@ -391,12 +1549,33 @@ type OutputJobRequest struct {
 	unknownFields protoimpl.UnknownFields
 
 	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// start_offset starts the output at the given byte offset, such as the
+	// offset just past the last chunk received before a client was
+	// disconnected. It can't be past the end of the output so far.
+	StartOffset int64 `protobuf:"varint,2,opt,name=start_offset,json=startOffset,proto3" json:"start_offset,omitempty"`
+	// tail_lines, if set, starts the output at the beginning of the last
+	// tail_lines lines written so far; zero means only output written from
+	// now on is sent. It can't be used with start_offset.
+	TailLines *int32 `protobuf:"varint,3,opt,name=tail_lines,json=tailLines,proto3,oneof" json:"tail_lines,omitempty"`
+	// follow, if set to false, ends the stream once the output written so
+	// far has been sent, instead of waiting for the job to end. It defaults
+	// to true.
+	Follow *bool `protobuf:"varint,4,opt,name=follow,proto3,oneof" json:"follow,omitempty"`
+	// stream only sends the output the job wrote to the given stream. By
+	// default both are sent, in the order they were captured ( see
+	// Combined ). tail_lines
+	// only counts lines from the given stream, but offsets always count the
+	// output of both streams.
+	Stream OutputStream `protobuf:"varint,5,opt,name=stream,proto3,enum=seanhagen.pb.OutputStream" json:"stream,omitempty"`
+	// timestamps, if set, sets the time field of each response to when the
+	// job wrote the data in it. The data itself is never changed.
+	Timestamps bool `protobuf:"varint,6,opt,name=timestamps,proto3" json:"timestamps,omitempty"`
 }
 
 func (x *OutputJobRequest) Reset() {
 	*x = OutputJobRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_workernator_proto_msgTypes[5]
+		mi := &file_workernator_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -409,7 +1588,7 @@ func (x *OutputJobRequest) String() string {
 func (*OutputJobRequest) ProtoMessage() {}
 
 func (x *OutputJobRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_workernator_proto_msgTypes[5]
+	mi := &file_workernator_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -422,7 +1601,7 @@ func (x *OutputJobRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OutputJobRequest.ProtoReflect.Descriptor instead.
 func (*OutputJobRequest) Descriptor() ([]byte, []int) {
-	return file_workernator_proto_rawDescGZIP(), []int{5}
+	return file_workernator_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *OutputJobRequest) GetId() string {
@@ -432,6 +1611,41 @@ func (x *OutputJobRequest) GetId() string {
 	return ""
 }
 
+func (x *OutputJobRequest) GetStartOffset() int64 {
+	if x != nil {
+		return x.StartOffset
+	}
+	return 0
+}
+
+func (x *OutputJobRequest) GetTailLines() int32 {
+	if x != nil && x.TailLines != nil {
+		return *x.TailLines
+	}
+	return 0
+}
+
+func (x *OutputJobRequest) GetFollow() bool {
+	if x != nil && x.Follow != nil {
+		return *x.Follow
+	}
+	return false
+}
+
+func (x *OutputJobRequest) GetStream() OutputStream {
+	if x != nil {
+		return x.Stream
+	}
+	return OutputStream_Combined
+}
+
+func (x *OutputJobRequest) GetTimestamps() bool {
+	if x != nil {
+		return x.Timestamps
+	}
+	return false
+}
+
 // OutputJobResponse contains the binary output of a job, regardless of whether
 // the job is outputting text or actual binary data ( like a file ).
 type OutputJobResponse struct {
@@ -440,12 +1654,22 @@ type OutputJobResponse struct {
 	unknownFields protoimpl.UnknownFields
 
 	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// offset is the byte offset of the start of data in the output of the
+	// job.
+	Offset int64 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// stream is the stream data came from, either Stdout or Stderr. A
+	// single response never mixes the two.
+	Stream OutputStream `protobuf:"varint,3,opt,name=stream,proto3,enum=seanhagen.pb.OutputStream" json:"stream,omitempty"`
+	// time is when the job wrote data, and is only set if timestamps was
+	// set in the request. A single response never has data written at
+	// different times.
+	Time *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
 }
 
 func (x *OutputJobResponse) Reset() {
 	*x = OutputJobResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_workernator_proto_msgTypes[6]
+		mi := &file_workernator_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -458,7 +1682,7 @@ func (x *OutputJobResponse) String() string {
 func (*OutputJobResponse) ProtoMessage() {}
 
 func (x *OutputJobResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_workernator_proto_msgTypes[6]
+	mi := &file_workernator_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -471,7 +1695,7 @@ func (x *OutputJobResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OutputJobResponse.ProtoReflect.Descriptor instead.
 func (*OutputJobResponse) Descriptor() ([]byte, []int) {
-	return file_workernator_proto_rawDescGZIP(), []int{6}
+	return file_workernator_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *OutputJobResponse) GetData() []byte {
@@ -481,72 +1705,288 @@ func (x *OutputJobResponse) GetData() []byte {
 	return nil
 }
 
+func (x *OutputJobResponse) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *OutputJobResponse) GetStream() OutputStream {
+	if x != nil {
+		return x.Stream
+	}
+	return OutputStream_Combined
+}
+
+func (x *OutputJobResponse) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
 var File_workernator_proto protoreflect.FileDescriptor
 
 var file_workernator_proto_rawDesc = []byte{
 	0x0a, 0x11, 0x77, 0x6f, 0x72, 0x6b, 0x65, 0x72, 0x6e, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72,
 	0x6f, 0x74, 0x6f, 0x12, 0x0c, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70,
-	0x62, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x62, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
 	0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x22, 0x83, 0x02, 0x0a, 0x03, 0x4a, 0x6f, 0x62, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x2f, 0x0a, 0x06, 0x73, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x73, 0x65, 0x61,
-	0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x72, 0x67, 0x73, 0x18, 0x0c, 0x20,
-	0x03, 0x28, 0x09, 0x52, 0x04, 0x61, 0x72, 0x67, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x65, 0x72, 0x72,
-	0x6f, 0x72, 0x5f, 0x6d, 0x73, 0x67, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x72,
-	0x72, 0x6f, 0x72, 0x4d, 0x73, 0x67, 0x12, 0x39, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65,
-	0x64, 0x5f, 0x61, 0x74, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
-	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
-	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x41,
-	0x74, 0x12, 0x35, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x16, 0x20,
+	0x74, 0x6f, 0x22, 0x7d, 0x0a, 0x07, 0x49, 0x4f, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x2b, 0x0a, 0x12, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x62, 0x79,
+	0x74, 0x65, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0f, 0x72, 0x65, 0x61, 0x64, 0x42, 0x79, 0x74, 0x65, 0x73, 0x50, 0x65, 0x72, 0x53,
+	0x65, 0x63, 0x12, 0x2d, 0x0a, 0x13, 0x77, 0x72, 0x69, 0x74, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65,
+	0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x10, 0x77, 0x72, 0x69, 0x74, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x50, 0x65, 0x72, 0x53, 0x65,
+	0x63, 0x22, 0xe7, 0x01, 0x0a, 0x0e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4c, 0x69,
+	0x6d, 0x69, 0x74, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x5f, 0x70, 0x69, 0x64, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x6d, 0x61, 0x78, 0x50, 0x69, 0x64, 0x73, 0x12,
+	0x36, 0x0a, 0x09, 0x63, 0x70, 0x75, 0x5f, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x63,
+	0x70, 0x75, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x38, 0x0a, 0x0a, 0x63, 0x70, 0x75, 0x5f, 0x70,
+	0x65, 0x72, 0x69, 0x6f, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x63, 0x70, 0x75, 0x50, 0x65, 0x72, 0x69, 0x6f,
+	0x64, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x79, 0x74, 0x65,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42,
+	0x79, 0x74, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x02, 0x69, 0x6f, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x15, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e,
+	0x49, 0x4f, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x52, 0x02, 0x69, 0x6f, 0x22, 0xd5, 0x04, 0x0a, 0x03,
+	0x4a, 0x6f, 0x62, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x2f, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e,
+	0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x12,
+	0x0a, 0x04, 0x61, 0x72, 0x67, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x61, 0x72,
+	0x67, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x6d, 0x73, 0x67, 0x18,
+	0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x4d, 0x73, 0x67, 0x12,
+	0x34, 0x0a, 0x06, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1c, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x52,
+	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x52, 0x06, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x0f,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x69, 0x67, 0x6e, 0x61, 0x6c, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x69, 0x67,
+	0x6e, 0x61, 0x6c, 0x12, 0x4e, 0x0a, 0x12, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x5f, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x13, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x1f, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x54,
+	0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x52, 0x11, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x61,
+	0x73, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x65, 0x78, 0x69, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65,
+	0x18, 0x11, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65,
+	0x12, 0x31, 0x0a, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x52,
+	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x05, 0x75, 0x73,
+	0x61, 0x67, 0x65, 0x12, 0x3a, 0x0a, 0x0b, 0x6d, 0x61, 0x78, 0x5f, 0x72, 0x75, 0x6e, 0x74, 0x69,
+	0x6d, 0x65, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x6d, 0x61, 0x78, 0x52, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x12,
+	0x39, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x15, 0x20,
 	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
 	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52,
-	0x07, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x41, 0x74, 0x22, 0x49, 0x0a, 0x0f, 0x4a, 0x6f, 0x62, 0x53,
-	0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x63,
-	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f,
-	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x72, 0x67, 0x75, 0x6d, 0x65, 0x6e,
-	0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x61, 0x72, 0x67, 0x75, 0x6d, 0x65,
-	0x6e, 0x74, 0x73, 0x22, 0x20, 0x0a, 0x0e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65,
+	0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x35, 0x0a, 0x08, 0x65, 0x6e,
+	0x64, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x16, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x65, 0x64, 0x41,
+	0x74, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x79, 0x18, 0x17, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03,
+	0x74, 0x74, 0x79, 0x22, 0xf5, 0x01, 0x0a, 0x0d, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x34, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x63, 0x70,
+	0x75, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x07, 0x75, 0x73, 0x65, 0x72, 0x43, 0x70, 0x75, 0x12, 0x38, 0x0a, 0x0a, 0x73,
+	0x79, 0x73, 0x74, 0x65, 0x6d, 0x5f, 0x63, 0x70, 0x75, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x73, 0x79, 0x73, 0x74,
+	0x65, 0x6d, 0x43, 0x70, 0x75, 0x12, 0x2a, 0x0a, 0x11, 0x70, 0x65, 0x61, 0x6b, 0x5f, 0x6d, 0x65,
+	0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0f, 0x70, 0x65, 0x61, 0x6b, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x12, 0x22, 0x0a, 0x0d, 0x69, 0x6f, 0x5f, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x62, 0x79, 0x74,
+	0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x69, 0x6f, 0x52, 0x65, 0x61, 0x64,
+	0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x69, 0x6f, 0x5f, 0x77, 0x72, 0x69, 0x74,
+	0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x69,
+	0x6f, 0x57, 0x72, 0x69, 0x74, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0xe3, 0x01, 0x0a, 0x0f,
+	0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x72, 0x67,
+	0x75, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x61, 0x72,
+	0x67, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x34, 0x0a, 0x06, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61,
+	0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4c,
+	0x69, 0x6d, 0x69, 0x74, 0x73, 0x52, 0x06, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x3a, 0x0a,
+	0x0b, 0x6d, 0x61, 0x78, 0x5f, 0x72, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x6d,
+	0x61, 0x78, 0x52, 0x75, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x64,
+	0x69, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x73, 0x74, 0x64, 0x69, 0x6e, 0x12,
+	0x10, 0x0a, 0x03, 0x74, 0x74, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x74, 0x74,
+	0x79, 0x22, 0x5e, 0x0a, 0x0e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x3c, 0x0a, 0x0c, 0x67, 0x72, 0x61, 0x63, 0x65, 0x5f, 0x70, 0x65, 0x72,
+	0x69, 0x6f, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x67, 0x72, 0x61, 0x63, 0x65, 0x50, 0x65, 0x72, 0x69, 0x6f,
+	0x64, 0x22, 0x22, 0x0a, 0x10, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65,
 	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x22, 0x0a, 0x10, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x38, 0x0a, 0x11, 0x4a, 0x6f, 0x62,
-	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23,
-	0x0a, 0x03, 0x6a, 0x6f, 0x62, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x73, 0x65,
-	0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x52, 0x03,
-	0x6a, 0x6f, 0x62, 0x22, 0x22, 0x0a, 0x10, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x4a, 0x6f, 0x62,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x27, 0x0a, 0x11, 0x4f, 0x75, 0x74, 0x70, 0x75,
-	0x74, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04,
-	0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61,
-	0x2a, 0x4c, 0x0a, 0x09, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0b, 0x0a,
-	0x07, 0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x52, 0x75,
-	0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x46, 0x61, 0x69, 0x6c, 0x65,
-	0x64, 0x10, 0x02, 0x12, 0x0c, 0x0a, 0x08, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x10,
-	0x03, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x74, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x10, 0x04, 0x32, 0x8f,
-	0x02, 0x0a, 0x07, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x3b, 0x0a, 0x05, 0x53, 0x74,
-	0x61, 0x72, 0x74, 0x12, 0x1d, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e,
-	0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x1a, 0x11, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70,
-	0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x22, 0x00, 0x12, 0x39, 0x0a, 0x04, 0x53, 0x74, 0x6f, 0x70, 0x12,
-	0x1c, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a,
-	0x6f, 0x62, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e,
+	0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x38, 0x0a, 0x11, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a, 0x03, 0x6a, 0x6f,
+	0x62, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61,
+	0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x52, 0x03, 0x6a, 0x6f, 0x62, 0x22,
+	0xb5, 0x02, 0x0a, 0x0e, 0x4a, 0x6f, 0x62, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x33, 0x0a, 0x08, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e,
+	0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x08, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x65, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x18, 0x0a,
+	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x3f, 0x0a, 0x0d, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x65, 0x64, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0c, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x65, 0x64, 0x41, 0x66, 0x74, 0x65, 0x72, 0x12, 0x41, 0x0a, 0x0e, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x65, 0x64, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0d, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x65, 0x64, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70,
+	0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65,
+	0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61,
+	0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x60, 0x0a, 0x0f, 0x4a, 0x6f, 0x62, 0x4c, 0x69,
+	0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x25, 0x0a, 0x04, 0x6a, 0x6f,
+	0x62, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68,
+	0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x52, 0x04, 0x6a, 0x6f, 0x62,
+	0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74,
+	0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x39, 0x0a, 0x0f, 0x4a, 0x6f, 0x62,
+	0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03,
+	0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x03, 0x69, 0x64, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f,
+	0x77, 0x6e, 0x65, 0x72, 0x22, 0x8f, 0x01, 0x0a, 0x08, 0x4a, 0x6f, 0x62, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x1a, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a,
+	0x6f, 0x62, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x12, 0x23, 0x0a, 0x03, 0x6a, 0x6f, 0x62, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11,
+	0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f,
+	0x62, 0x52, 0x03, 0x6a, 0x6f, 0x62, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x22, 0x52, 0x0a, 0x0f, 0x4a, 0x6f, 0x62, 0x49, 0x6e, 0x70,
+	0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x1b, 0x0a,
+	0x09, 0x6b, 0x65, 0x65, 0x70, 0x5f, 0x6f, 0x70, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x08, 0x6b, 0x65, 0x65, 0x70, 0x4f, 0x70, 0x65, 0x6e, 0x22, 0x37, 0x0a, 0x10, 0x4a, 0x6f,
+	0x62, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23,
+	0x0a, 0x0d, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x74, 0x65, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x62, 0x79, 0x74, 0x65, 0x73, 0x57, 0x72, 0x69, 0x74,
+	0x74, 0x65, 0x6e, 0x22, 0x34, 0x0a, 0x0a, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x53, 0x69, 0x7a,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x04, 0x72, 0x6f, 0x77, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x6c, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x04, 0x63, 0x6f, 0x6c, 0x73, 0x22, 0x67, 0x0a, 0x0d, 0x41, 0x74, 0x74,
+	0x61, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e,
+	0x70, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74,
+	0x12, 0x30, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x18, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e,
+	0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x53, 0x69, 0x7a, 0x65, 0x52, 0x06, 0x72, 0x65, 0x73, 0x69,
+	0x7a, 0x65, 0x22, 0x28, 0x0a, 0x0e, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x22, 0xf4, 0x01, 0x0a,
+	0x10, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69,
+	0x64, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x6f, 0x66, 0x66, 0x73, 0x65,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x73, 0x74, 0x61, 0x72, 0x74, 0x4f, 0x66,
+	0x66, 0x73, 0x65, 0x74, 0x12, 0x22, 0x0a, 0x0a, 0x74, 0x61, 0x69, 0x6c, 0x5f, 0x6c, 0x69, 0x6e,
+	0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x48, 0x00, 0x52, 0x09, 0x74, 0x61, 0x69, 0x6c,
+	0x4c, 0x69, 0x6e, 0x65, 0x73, 0x88, 0x01, 0x01, 0x12, 0x1b, 0x0a, 0x06, 0x66, 0x6f, 0x6c, 0x6c,
+	0x6f, 0x77, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x48, 0x01, 0x52, 0x06, 0x66, 0x6f, 0x6c, 0x6c,
+	0x6f, 0x77, 0x88, 0x01, 0x01, 0x12, 0x32, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65,
+	0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x73, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x74, 0x61,
+	0x69, 0x6c, 0x5f, 0x6c, 0x69, 0x6e, 0x65, 0x73, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x66, 0x6f, 0x6c,
+	0x6c, 0x6f, 0x77, 0x22, 0xa3, 0x01, 0x0a, 0x11, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x4a, 0x6f,
+	0x62, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x16, 0x0a,
+	0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6f,
+	0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x32, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1a, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65,
+	0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x2a, 0x5a, 0x0a, 0x09, 0x4a, 0x6f, 0x62,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x6e, 0x6b, 0x6e, 0x6f, 0x77,
+	0x6e, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x52, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x10, 0x01,
+	0x12, 0x0a, 0x0a, 0x06, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x10, 0x02, 0x12, 0x0c, 0x0a, 0x08,
+	0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x10, 0x03, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x74,
+	0x6f, 0x70, 0x70, 0x65, 0x64, 0x10, 0x04, 0x12, 0x0c, 0x0a, 0x08, 0x54, 0x69, 0x6d, 0x65, 0x64,
+	0x4f, 0x75, 0x74, 0x10, 0x05, 0x2a, 0x80, 0x01, 0x0a, 0x11, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x11, 0x0a, 0x0d, 0x4e,
+	0x6f, 0x74, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x64, 0x10, 0x00, 0x12, 0x0a,
+	0x0a, 0x06, 0x45, 0x78, 0x69, 0x74, 0x65, 0x64, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x69,
+	0x67, 0x6e, 0x61, 0x6c, 0x65, 0x64, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x55, 0x73, 0x65, 0x72,
+	0x53, 0x74, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x10, 0x03, 0x12, 0x0d, 0x0a, 0x09, 0x4f, 0x4f, 0x4d,
+	0x4b, 0x69, 0x6c, 0x6c, 0x65, 0x64, 0x10, 0x04, 0x12, 0x11, 0x0a, 0x0d, 0x50, 0x69, 0x64, 0x73,
+	0x45, 0x78, 0x68, 0x61, 0x75, 0x73, 0x74, 0x65, 0x64, 0x10, 0x05, 0x12, 0x0b, 0x0a, 0x07, 0x54,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x10, 0x06, 0x2a, 0x81, 0x01, 0x0a, 0x0c, 0x4a, 0x6f, 0x62,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x10, 0x0a, 0x0c, 0x55, 0x6e, 0x6b,
+	0x6e, 0x6f, 0x77, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x4a,
+	0x6f, 0x62, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x4a,
+	0x6f, 0x62, 0x53, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x10, 0x02, 0x12, 0x0e, 0x0a, 0x0a, 0x4a,
+	0x6f, 0x62, 0x53, 0x74, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x10, 0x03, 0x12, 0x0f, 0x0a, 0x0b, 0x4a,
+	0x6f, 0x62, 0x54, 0x69, 0x6d, 0x65, 0x64, 0x4f, 0x75, 0x74, 0x10, 0x04, 0x12, 0x0f, 0x0a, 0x0b,
+	0x4a, 0x6f, 0x62, 0x46, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x10, 0x05, 0x12, 0x0d, 0x0a,
+	0x09, 0x4a, 0x6f, 0x62, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x10, 0x06, 0x2a, 0x34, 0x0a, 0x0c,
+	0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x0c, 0x0a, 0x08,
+	0x43, 0x6f, 0x6d, 0x62, 0x69, 0x6e, 0x65, 0x64, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x74,
+	0x64, 0x6f, 0x75, 0x74, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x74, 0x64, 0x65, 0x72, 0x72,
+	0x10, 0x02, 0x32, 0xb1, 0x04, 0x0a, 0x07, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x3b,
+	0x0a, 0x05, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x1d, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61,
+	0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x72, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67,
+	0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x22, 0x00, 0x12, 0x39, 0x0a, 0x04, 0x53,
+	0x74, 0x6f, 0x70, 0x12, 0x1c, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e,
+	0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x11, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62,
+	0x2e, 0x4a, 0x6f, 0x62, 0x22, 0x00, 0x12, 0x3d, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x12, 0x1e, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e,
+	0x4a, 0x6f, 0x62, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x11, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e,
+	0x4a, 0x6f, 0x62, 0x22, 0x00, 0x12, 0x45, 0x0a, 0x04, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x1c, 0x2e,
 	0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62,
-	0x22, 0x00, 0x12, 0x3d, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1e, 0x2e, 0x73,
-	0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x53,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x73,
-	0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x22,
-	0x00, 0x12, 0x4d, 0x0a, 0x06, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x1e, 0x2e, 0x73, 0x65,
-	0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75,
-	0x74, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x73, 0x65,
-	0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75,
-	0x74, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01,
-	0x42, 0x22, 0x5a, 0x20, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73,
-	0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61,
-	0x6c, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x73, 0x65,
+	0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x4c, 0x69,
+	0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x42, 0x0a, 0x05,
+	0x57, 0x61, 0x74, 0x63, 0x68, 0x12, 0x1d, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65,
+	0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e,
+	0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x00, 0x30, 0x01,
+	0x12, 0x4a, 0x0a, 0x05, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x1d, 0x2e, 0x73, 0x65, 0x61, 0x6e,
+	0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x49, 0x6e, 0x70, 0x75,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68,
+	0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x4a, 0x6f, 0x62, 0x49, 0x6e, 0x70, 0x75, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x28, 0x01, 0x12, 0x49, 0x0a, 0x06,
+	0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x12, 0x1b, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67,
+	0x65, 0x6e, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e,
+	0x70, 0x62, 0x2e, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x12, 0x4d, 0x0a, 0x06, 0x4f, 0x75, 0x74, 0x70, 0x75,
+	0x74, 0x12, 0x1e, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62,
+	0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1f, 0x2e, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2e, 0x70, 0x62,
+	0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x4a, 0x6f, 0x62, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x42, 0x22, 0x5a, 0x20, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x65, 0x61, 0x6e, 0x68, 0x61, 0x67, 0x65, 0x6e, 0x2f, 0x69,
+	0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
 }
 
 var (
@@ -561,37 +2001,84 @@ func file_workernator_proto_rawDescGZIP() []byte {
 	return file_workernator_proto_rawDescData
 }
 
-var file_workernator_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_workernator_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_workernator_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_workernator_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
 var file_workernator_proto_goTypes = []interface{}{
 	(JobStatus)(0),                // 0: seanhagen.pb.JobStatus
-	(*Job)(nil),                   // 1: seanhagen.pb.Job
-	(*JobStartRequest)(nil),       // 2: seanhagen.pb.JobStartRequest
-	(*JobStopRequest)(nil),        // 3: seanhagen.pb.JobStopRequest
-	(*JobStatusRequest)(nil),      // 4: seanhagen.pb.JobStatusRequest
-	(*JobStatusResponse)(nil),     // 5: seanhagen.pb.JobStatusResponse
-	(*OutputJobRequest)(nil),      // 6: seanhagen.pb.OutputJobRequest
-	(*OutputJobResponse)(nil),     // 7: seanhagen.pb.OutputJobResponse
-	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+	(TerminationReason)(0),        // 1: seanhagen.pb.TerminationReason
+	(JobEventType)(0),             // 2: seanhagen.pb.JobEventType
+	(OutputStream)(0),             // 3: seanhagen.pb.OutputStream
+	(*IOLimit)(nil),               // 4: seanhagen.pb.IOLimit
+	(*ResourceLimits)(nil),        // 5: seanhagen.pb.ResourceLimits
+	(*Job)(nil),                   // 6: seanhagen.pb.Job
+	(*ResourceUsage)(nil),         // 7: seanhagen.pb.ResourceUsage
+	(*JobStartRequest)(nil),       // 8: seanhagen.pb.JobStartRequest
+	(*JobStopRequest)(nil),        // 9: seanhagen.pb.JobStopRequest
+	(*JobStatusRequest)(nil),      // 10: seanhagen.pb.JobStatusRequest
+	(*JobStatusResponse)(nil),     // 11: seanhagen.pb.JobStatusResponse
+	(*JobListRequest)(nil),        // 12: seanhagen.pb.JobListRequest
+	(*JobListResponse)(nil),       // 13: seanhagen.pb.JobListResponse
+	(*JobWatchRequest)(nil),       // 14: seanhagen.pb.JobWatchRequest
+	(*JobEvent)(nil),              // 15: seanhagen.pb.JobEvent
+	(*JobInputRequest)(nil),       // 16: seanhagen.pb.JobInputRequest
+	(*JobInputResponse)(nil),      // 17: seanhagen.pb.JobInputResponse
+	(*WindowSize)(nil),            // 18: seanhagen.pb.WindowSize
+	(*AttachRequest)(nil),         // 19: seanhagen.pb.AttachRequest
+	(*AttachResponse)(nil),        // 20: seanhagen.pb.AttachResponse
+	(*OutputJobRequest)(nil),      // 21: seanhagen.pb.OutputJobRequest
+	(*OutputJobResponse)(nil),     // 22: seanhagen.pb.OutputJobResponse
+	(*durationpb.Duration)(nil),   // 23: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil), // 24: google.protobuf.Timestamp
 }
 var file_workernator_proto_depIdxs = []int32{
-	0, // 0: seanhagen.pb.Job.status:type_name -> seanhagen.pb.JobStatus
-	8, // 1: seanhagen.pb.Job.started_at:type_name -> google.protobuf.Timestamp
-	8, // 2: seanhagen.pb.Job.ended_at:type_name -> google.protobuf.Timestamp
-	1, // 3: seanhagen.pb.JobStatusResponse.job:type_name -> seanhagen.pb.Job
-	2, // 4: seanhagen.pb.Service.Start:input_type -> seanhagen.pb.JobStartRequest
-	3, // 5: seanhagen.pb.Service.Stop:input_type -> seanhagen.pb.JobStopRequest
-	4, // 6: seanhagen.pb.Service.Status:input_type -> seanhagen.pb.JobStatusRequest
-	6, // 7: seanhagen.pb.Service.Output:input_type -> seanhagen.pb.OutputJobRequest
-	1, // 8: seanhagen.pb.Service.Start:output_type -> seanhagen.pb.Job
-	1, // 9: seanhagen.pb.Service.Stop:output_type -> seanhagen.pb.Job
-	1, // 10: seanhagen.pb.Service.Status:output_type -> seanhagen.pb.Job
-	7, // 11: seanhagen.pb.Service.Output:output_type -> seanhagen.pb.OutputJobResponse
-	8, // [8:12] is the sub-list for method output_type
-	4, // [4:8] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	23, // 0: seanhagen.pb.ResourceLimits.cpu_quota:type_name -> google.protobuf.Duration
+	23, // 1: seanhagen.pb.ResourceLimits.cpu_period:type_name -> google.protobuf.Duration
+	4,  // 2: seanhagen.pb.ResourceLimits.io:type_name -> seanhagen.pb.IOLimit
+	0,  // 3: seanhagen.pb.Job.status:type_name -> seanhagen.pb.JobStatus
+	5,  // 4: seanhagen.pb.Job.limits:type_name -> seanhagen.pb.ResourceLimits
+	1,  // 5: seanhagen.pb.Job.termination_reason:type_name -> seanhagen.pb.TerminationReason
+	7,  // 6: seanhagen.pb.Job.usage:type_name -> seanhagen.pb.ResourceUsage
+	23, // 7: seanhagen.pb.Job.max_runtime:type_name -> google.protobuf.Duration
+	24, // 8: seanhagen.pb.Job.started_at:type_name -> google.protobuf.Timestamp
+	24, // 9: seanhagen.pb.Job.ended_at:type_name -> google.protobuf.Timestamp
+	23, // 10: seanhagen.pb.ResourceUsage.user_cpu:type_name -> google.protobuf.Duration
+	23, // 11: seanhagen.pb.ResourceUsage.system_cpu:type_name -> google.protobuf.Duration
+	5,  // 12: seanhagen.pb.JobStartRequest.limits:type_name -> seanhagen.pb.ResourceLimits
+	23, // 13: seanhagen.pb.JobStartRequest.max_runtime:type_name -> google.protobuf.Duration
+	23, // 14: seanhagen.pb.JobStopRequest.grace_period:type_name -> google.protobuf.Duration
+	6,  // 15: seanhagen.pb.JobStatusResponse.job:type_name -> seanhagen.pb.Job
+	0,  // 16: seanhagen.pb.JobListRequest.statuses:type_name -> seanhagen.pb.JobStatus
+	24, // 17: seanhagen.pb.JobListRequest.started_after:type_name -> google.protobuf.Timestamp
+	24, // 18: seanhagen.pb.JobListRequest.started_before:type_name -> google.protobuf.Timestamp
+	6,  // 19: seanhagen.pb.JobListResponse.jobs:type_name -> seanhagen.pb.Job
+	2,  // 20: seanhagen.pb.JobEvent.type:type_name -> seanhagen.pb.JobEventType
+	6,  // 21: seanhagen.pb.JobEvent.job:type_name -> seanhagen.pb.Job
+	24, // 22: seanhagen.pb.JobEvent.time:type_name -> google.protobuf.Timestamp
+	18, // 23: seanhagen.pb.AttachRequest.resize:type_name -> seanhagen.pb.WindowSize
+	3,  // 24: seanhagen.pb.OutputJobRequest.stream:type_name -> seanhagen.pb.OutputStream
+	3,  // 25: seanhagen.pb.OutputJobResponse.stream:type_name -> seanhagen.pb.OutputStream
+	24, // 26: seanhagen.pb.OutputJobResponse.time:type_name -> google.protobuf.Timestamp
+	8,  // 27: seanhagen.pb.Service.Start:input_type -> seanhagen.pb.JobStartRequest
+	9,  // 28: seanhagen.pb.Service.Stop:input_type -> seanhagen.pb.JobStopRequest
+	10, // 29: seanhagen.pb.Service.Status:input_type -> seanhagen.pb.JobStatusRequest
+	12, // 30: seanhagen.pb.Service.List:input_type -> seanhagen.pb.JobListRequest
+	14, // 31: seanhagen.pb.Service.Watch:input_type -> seanhagen.pb.JobWatchRequest
+	16, // 32: seanhagen.pb.Service.Input:input_type -> seanhagen.pb.JobInputRequest
+	19, // 33: seanhagen.pb.Service.Attach:input_type -> seanhagen.pb.AttachRequest
+	21, // 34: seanhagen.pb.Service.Output:input_type -> seanhagen.pb.OutputJobRequest
+	6,  // 35: seanhagen.pb.Service.Start:output_type -> seanhagen.pb.Job
+	6,  // 36: seanhagen.pb.Service.Stop:output_type -> seanhagen.pb.Job
+	6,  // 37: seanhagen.pb.Service.Status:output_type -> seanhagen.pb.Job
+	13, // 38: seanhagen.pb.Service.List:output_type -> seanhagen.pb.JobListResponse
+	15, // 39: seanhagen.pb.Service.Watch:output_type -> seanhagen.pb.JobEvent
+	17, // 40: seanhagen.pb.Service.Input:output_type -> seanhagen.pb.JobInputResponse
+	20, // 41: seanhagen.pb.Service.Attach:output_type -> seanhagen.pb.AttachResponse
+	22, // 42: seanhagen.pb.Service.Output:output_type -> seanhagen.pb.OutputJobResponse
+	35, // [35:43] is the sub-list for method output_type
+	27, // [27:35] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
 }
 
 func init() { file_workernator_proto_init() }
@@ -601,7 +2088,7 @@ func file_workernator_proto_init() {
 	}
 	if !protoimpl.UnsafeEnabled {
 		file_workernator_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Job); i {
+			switch v := v.(*IOLimit); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -613,7 +2100,7 @@ func file_workernator_proto_init() {
 			}
 		}
 		file_workernator_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*JobStartRequest); i {
+			switch v := v.(*ResourceLimits); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -625,7 +2112,7 @@ func file_workernator_proto_init() {
 			}
 		}
 		file_workernator_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*JobStopRequest); i {
+			switch v := v.(*Job); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -637,7 +2124,7 @@ func file_workernator_proto_init() {
 			}
 		}
 		file_workernator_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*JobStatusRequest); i {
+			switch v := v.(*ResourceUsage); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -649,7 +2136,7 @@ func file_workernator_proto_init() {
 			}
 		}
 		file_workernator_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*JobStatusResponse); i {
+			switch v := v.(*JobStartRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -661,7 +2148,7 @@ func file_workernator_proto_init() {
 			}
 		}
 		file_workernator_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*OutputJobRequest); i {
+			switch v := v.(*JobStopRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -673,6 +2160,150 @@ func file_workernator_proto_init() {
 			}
 		}
 		file_workernator_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JobStatusRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_workernator_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JobStatusResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_workernator_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JobListRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_workernator_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JobListResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_workernator_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JobWatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_workernator_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JobEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_workernator_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JobInputRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_workernator_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JobInputResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_workernator_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WindowSize); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_workernator_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AttachRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_workernator_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AttachResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_workernator_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OutputJobRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_workernator_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*OutputJobResponse); i {
 			case 0:
 				return &v.state
@@ -685,13 +2316,14 @@ func file_workernator_proto_init() {
 			}
 		}
 	}
+	file_workernator_proto_msgTypes[17].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_workernator_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   7,
+			NumEnums:      4,
+			NumMessages:   19,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
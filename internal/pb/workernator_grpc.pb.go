@@ -39,8 +39,44 @@ type ServiceClient interface {
 	// Will return an error if the ID provided doesn't map to any known
 	// jobs.
 	Status(ctx context.Context, in *JobStatusRequest, opts ...grpc.CallOption) (*Job, error)
-	// Output returns a stream of log lines from the job. It always
-	// returns the full log from the beginning of job execution.
+	// List returns the jobs known to the service that match the filters in
+	// the request, a page at a time. Users that can only interact with
+	// their own jobs only see their own jobs.
+	List(ctx context.Context, in *JobListRequest, opts ...grpc.CallOption) (*JobListResponse, error)
+	// Watch streams events for jobs as they're created, start, and end,
+	// until the client cancels the request. Only events that happen after
+	// the request is made are sent. Users that can only interact with their
+	// own jobs only get events for their own jobs. The response headers
+	// are sent as soon as the service is ready to send events, so no events
+	// are missed after they've been received.
+	//
+	// The stream ends with an Aborted error if the client doesn't read
+	// events quickly enough, and with an Unavailable error when the service
+	// is shutting down.
+	Watch(ctx context.Context, in *JobWatchRequest, opts ...grpc.CallOption) (Service_WatchClient, error)
+	// Input writes the data sent to the stdin of a job, which must have
+	// been started with stdin set. Once the client closes its side of the
+	// stream the stdin of the job is closed too, unless the last message
+	// had keep_open set. If the stream is cancelled stdin is also left
+	// open, but data sent just before may not have been written. Only one
+	// call can send input to a job at a time.
+	//
+	// Will return a FailedPrecondition error if the job isn't accepting
+	// input.
+	Input(ctx context.Context, opts ...grpc.CallOption) (Service_InputClient, error)
+	// Attach connects to the terminal of a job, which must have been
+	// started with tty set. Input sent by the client is written to the
+	// terminal, and output the job writes to it from then on is sent back.
+	// The stream ends once the job has ended, or once the client closes
+	// its side of the stream to detach, which leaves the job running. Only
+	// one client can send input to a job at a time.
+	//
+	// Will return a FailedPrecondition error if the job doesn't have a
+	// terminal, or is already attached to.
+	Attach(ctx context.Context, opts ...grpc.CallOption) (Service_AttachClient, error)
+	// Output returns a stream of log lines from the job. By default it
+	// returns the full log from the beginning of job execution, and keeps
+	// sending new output until the job ends.
 	//
 	// Will return an error if the ID provided doesn't map to any known
 	// jobs.
@@ -82,8 +118,114 @@ func (c *serviceClient) Status(ctx context.Context, in *JobStatusRequest, opts .
 	return out, nil
 }
 
+func (c *serviceClient) List(ctx context.Context, in *JobListRequest, opts ...grpc.CallOption) (*JobListResponse, error) {
+	out := new(JobListResponse)
+	err := c.cc.Invoke(ctx, "/seanhagen.pb.Service/List", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serviceClient) Watch(ctx context.Context, in *JobWatchRequest, opts ...grpc.CallOption) (Service_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Service_ServiceDesc.Streams[0], "/seanhagen.pb.Service/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serviceWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Service_WatchClient interface {
+	Recv() (*JobEvent, error)
+	grpc.ClientStream
+}
+
+type serviceWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *serviceWatchClient) Recv() (*JobEvent, error) {
+	m := new(JobEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serviceClient) Input(ctx context.Context, opts ...grpc.CallOption) (Service_InputClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Service_ServiceDesc.Streams[1], "/seanhagen.pb.Service/Input", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serviceInputClient{stream}
+	return x, nil
+}
+
+type Service_InputClient interface {
+	Send(*JobInputRequest) error
+	CloseAndRecv() (*JobInputResponse, error)
+	grpc.ClientStream
+}
+
+type serviceInputClient struct {
+	grpc.ClientStream
+}
+
+func (x *serviceInputClient) Send(m *JobInputRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *serviceInputClient) CloseAndRecv() (*JobInputResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(JobInputResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *serviceClient) Attach(ctx context.Context, opts ...grpc.CallOption) (Service_AttachClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Service_ServiceDesc.Streams[2], "/seanhagen.pb.Service/Attach", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serviceAttachClient{stream}
+	return x, nil
+}
+
+type Service_AttachClient interface {
+	Send(*AttachRequest) error
+	Recv() (*AttachResponse, error)
+	grpc.ClientStream
+}
+
+type serviceAttachClient struct {
+	grpc.ClientStream
+}
+
+func (x *serviceAttachClient) Send(m *AttachRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *serviceAttachClient) Recv() (*AttachResponse, error) {
+	m := new(AttachResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *serviceClient) Output(ctx context.Context, in *OutputJobRequest, opts ...grpc.CallOption) (Service_OutputClient, error) {
-	stream, err := c.cc.NewStream(ctx, &Service_ServiceDesc.Streams[0], "/seanhagen.pb.Service/Output", opts...)
+	stream, err := c.cc.NewStream(ctx, &Service_ServiceDesc.Streams[3], "/seanhagen.pb.Service/Output", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -139,8 +281,44 @@ type ServiceServer interface {
 	// Will return an error if the ID provided doesn't map to any known
 	// jobs.
 	Status(context.Context, *JobStatusRequest) (*Job, error)
-	// Output returns a stream of log lines from the job. It always
-	// returns the full log from the beginning of job execution.
+	// List returns the jobs known to the service that match the filters in
+	// the request, a page at a time. Users that can only interact with
+	// their own jobs only see their own jobs.
+	List(context.Context, *JobListRequest) (*JobListResponse, error)
+	// Watch streams events for jobs as they're created, start, and end,
+	// until the client cancels the request. Only events that happen after
+	// the request is made are sent. Users that can only interact with their
+	// own jobs only get events for their own jobs. The response headers
+	// are sent as soon as the service is ready to send events, so no events
+	// are missed after they've been received.
+	//
+	// The stream ends with an Aborted error if the client doesn't read
+	// events quickly enough, and with an Unavailable error when the service
+	// is shutting down.
+	Watch(*JobWatchRequest, Service_WatchServer) error
+	// Input writes the data sent to the stdin of a job, which must have
+	// been started with stdin set. Once the client closes its side of the
+	// stream the stdin of the job is closed too, unless the last message
+	// had keep_open set. If the stream is cancelled stdin is also left
+	// open, but data sent just before may not have been written. Only one
+	// call can send input to a job at a time.
+	//
+	// Will return a FailedPrecondition error if the job isn't accepting
+	// input.
+	Input(Service_InputServer) error
+	// Attach connects to the terminal of a job, which must have been
+	// started with tty set. Input sent by the client is written to the
+	// terminal, and output the job writes to it from then on is sent back.
+	// The stream ends once the job has ended, or once the client closes
+	// its side of the stream to detach, which leaves the job running. Only
+	// one client can send input to a job at a time.
+	//
+	// Will return a FailedPrecondition error if the job doesn't have a
+	// terminal, or is already attached to.
+	Attach(Service_AttachServer) error
+	// Output returns a stream of log lines from the job. By default it
+	// returns the full log from the beginning of job execution, and keeps
+	// sending new output until the job ends.
 	//
 	// Will return an error if the ID provided doesn't map to any known
 	// jobs.
@@ -161,6 +339,18 @@ func (UnimplementedServiceServer) Stop(context.Context, *JobStopRequest) (*Job,
 func (UnimplementedServiceServer) Status(context.Context, *JobStatusRequest) (*Job, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
 }
+func (UnimplementedServiceServer) List(context.Context, *JobListRequest) (*JobListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedServiceServer) Watch(*JobWatchRequest, Service_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedServiceServer) Input(Service_InputServer) error {
+	return status.Errorf(codes.Unimplemented, "method Input not implemented")
+}
+func (UnimplementedServiceServer) Attach(Service_AttachServer) error {
+	return status.Errorf(codes.Unimplemented, "method Attach not implemented")
+}
 func (UnimplementedServiceServer) Output(*OutputJobRequest, Service_OutputServer) error {
 	return status.Errorf(codes.Unimplemented, "method Output not implemented")
 }
@@ -231,6 +421,97 @@ func _Service_Status_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Service_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JobListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServiceServer).List(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/seanhagen.pb.Service/List",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServiceServer).List(ctx, req.(*JobListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Service_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(JobWatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ServiceServer).Watch(m, &serviceWatchServer{stream})
+}
+
+type Service_WatchServer interface {
+	Send(*JobEvent) error
+	grpc.ServerStream
+}
+
+type serviceWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *serviceWatchServer) Send(m *JobEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Service_Input_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ServiceServer).Input(&serviceInputServer{stream})
+}
+
+type Service_InputServer interface {
+	SendAndClose(*JobInputResponse) error
+	Recv() (*JobInputRequest, error)
+	grpc.ServerStream
+}
+
+type serviceInputServer struct {
+	grpc.ServerStream
+}
+
+func (x *serviceInputServer) SendAndClose(m *JobInputResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *serviceInputServer) Recv() (*JobInputRequest, error) {
+	m := new(JobInputRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Service_Attach_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ServiceServer).Attach(&serviceAttachServer{stream})
+}
+
+type Service_AttachServer interface {
+	Send(*AttachResponse) error
+	Recv() (*AttachRequest, error)
+	grpc.ServerStream
+}
+
+type serviceAttachServer struct {
+	grpc.ServerStream
+}
+
+func (x *serviceAttachServer) Send(m *AttachResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *serviceAttachServer) Recv() (*AttachRequest, error) {
+	m := new(AttachRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Service_Output_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(OutputJobRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -271,8 +552,28 @@ var Service_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Status",
 			Handler:    _Service_Status_Handler,
 		},
+		{
+			MethodName: "List",
+			Handler:    _Service_List_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Service_Watch_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Input",
+			Handler:       _Service_Input_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Attach",
+			Handler:       _Service_Attach_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "Output",
 			Handler:       _Service_Output_Handler,
@@ -0,0 +1,170 @@
+// Package tlsconfig builds the mTLS credentials used by both the
+// workernator server and its clients, so that clients don't have to
+// depend on the server to connect to it.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// The subject fields every workernator certificate must have. The
+// common name is what separates server certificates from client
+// certificates, so that a client certificate can't be used to run a
+// server ( or the other way around ).
+const (
+	certOrganization = "Teleport"
+	certOrgUnit      = "workernator"
+
+	// ServerCommonName is the common name of a server certificate.
+	ServerCommonName = "server"
+	// ClientCommonName is the common name of a client certificate.
+	ClientCommonName = "client"
+)
+
+// ErrInvalidCertificate is returned when a certificate doesn't have the
+// subject fields expected of a workernator certificate.
+var ErrInvalidCertificate = errors.New("invalid certificate")
+
+// Config holds the paths to the files required to set up mTLS.
+type Config struct {
+	// RootCert is the path to the CA certificate used to verify the
+	// certificate of the other side of the connection.
+	RootCert string
+	// Cert is the path to the certificate for this side of the
+	// connection.
+	Cert string
+	// Key is the path to the private key for Cert.
+	Key string
+
+	// Time, if set, is used instead of time.Now when checking if a
+	// certificate has expired. This is really only useful for tests.
+	Time func() time.Time
+}
+
+// now is used when checking if a certificate has expired, unless a
+// Config sets its own Time.
+var now = time.Now
+
+// SetTime replaces the function used instead of time.Now when checking
+// if a certificate has expired, for every Config that doesn't set Time.
+// It lets tests keep using certificates that have since expired when
+// they can't reach the Config, such as the one built by the client
+// package, and must be called before any credentials are built.
+func SetTime(f func() time.Time) {
+	now = f
+}
+
+// timeFunc returns the function used to get the current time when
+// checking certificates.
+func (conf Config) timeFunc() func() time.Time {
+	if conf.Time != nil {
+		return conf.Time
+	}
+	return now
+}
+
+// ServerCredentials builds the transport credentials for the server.
+// Clients are required to provide a certificate signed by the root
+// certificate that has the subject fields of a workernator client
+// certificate, and TLS v1.3 is the minimum version allowed.
+func ServerCredentials(conf Config) (credentials.TransportCredentials, error) {
+	cert, pool, err := conf.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		MinVersion:            tls.VersionTLS13,
+		Certificates:          []tls.Certificate{cert},
+		ClientAuth:            tls.RequireAndVerifyClientCert,
+		ClientCAs:             pool,
+		Time:                  conf.timeFunc(),
+		VerifyPeerCertificate: verifySubject(ClientCommonName),
+	}), nil
+}
+
+// ClientCredentials builds the transport credentials for a client. The
+// server is required to have a certificate signed by the root
+// certificate that has the subject fields of a workernator server
+// certificate, and TLS v1.3 is the minimum version allowed.
+func ClientCredentials(conf Config) (credentials.TransportCredentials, error) {
+	cert, pool, err := conf.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		MinVersion:            tls.VersionTLS13,
+		Certificates:          []tls.Certificate{cert},
+		RootCAs:               pool,
+		Time:                  conf.timeFunc(),
+		VerifyPeerCertificate: verifySubject(ServerCommonName),
+	}), nil
+}
+
+// load reads the certificate, key, and root certificate from disk.
+func (conf Config) load() (tls.Certificate, *x509.CertPool, error) {
+	cert, err := tls.LoadX509KeyPair(conf.Cert, conf.Key)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("unable to load certificate '%v' and key '%v': %w", conf.Cert, conf.Key, err)
+	}
+
+	rootPEM, err := os.ReadFile(conf.RootCert)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("unable to read root certificate '%v': %w", conf.RootCert, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(rootPEM) {
+		return tls.Certificate{}, nil, fmt.Errorf("unable to parse root certificate '%v'", conf.RootCert)
+	}
+
+	return cert, pool, nil
+}
+
+// verifySubject returns a function for tls.Config.VerifyPeerCertificate
+// that checks the peer certificate has the subject fields expected for
+// a workernator certificate with the given common name. It's only
+// called once the certificate chain has been verified.
+func verifySubject(commonName string) func([][]byte, [][]*x509.Certificate) error {
+	return func(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if len(verifiedChains) == 0 || len(verifiedChains[0]) == 0 {
+			return fmt.Errorf("%w: no verified certificate", ErrInvalidCertificate)
+		}
+		return checkSubject(verifiedChains[0][0], commonName)
+	}
+}
+
+// checkSubject checks that cert has the O, OU, and CN subject fields
+// expected for a workernator certificate with the given common name.
+func checkSubject(cert *x509.Certificate, commonName string) error {
+	subject := cert.Subject
+
+	if !contains(subject.Organization, certOrganization) {
+		return fmt.Errorf("%w: organization must be '%v', got %v", ErrInvalidCertificate, certOrganization, subject.Organization)
+	}
+	if !contains(subject.OrganizationalUnit, certOrgUnit) {
+		return fmt.Errorf("%w: organizational unit must be '%v', got %v", ErrInvalidCertificate, certOrgUnit, subject.OrganizationalUnit)
+	}
+	if subject.CommonName != commonName {
+		return fmt.Errorf("%w: common name must be '%v', got '%v'", ErrInvalidCertificate, commonName, subject.CommonName)
+	}
+
+	return nil
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package tlsconfig
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the certificates are shared with the GRPC package tests
+func TestTLS_LoadErrors(t *testing.T) {
+	tests := map[string]Config{
+		"missing cert":     {RootCert: "../grpc/testdata/ca.pem", Cert: "../grpc/testdata/nope.pem", Key: "../grpc/testdata/cakey.key"},
+		"not a cert":       {RootCert: "../grpc/testdata/ca.pem", Cert: "../grpc/testdata/not-a-cert", Key: "../grpc/testdata/cakey.key"},
+		"missing root":     {RootCert: "../grpc/testdata/nope.pem", Cert: "../grpc/testdata/server.pem", Key: "../grpc/testdata/cakey.key"},
+		"root not a cert":  {RootCert: "../grpc/testdata/not-a-cert", Cert: "../grpc/testdata/server.pem", Key: "../grpc/testdata/cakey.key"},
+		"missing key":      {RootCert: "../grpc/testdata/ca.pem", Cert: "../grpc/testdata/server.pem", Key: "../grpc/testdata/nope.key"},
+		"key is not a key": {RootCert: "../grpc/testdata/ca.pem", Cert: "../grpc/testdata/server.pem", Key: "../grpc/testdata/not-a-cert"},
+	}
+
+	for name, conf := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ServerCredentials(conf)
+			assert.Error(t, err)
+			_, err = ClientCredentials(conf)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCheckSubject(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{
+		Organization:       []string{"Teleport"},
+		OrganizationalUnit: []string{"workernator"},
+		CommonName:         "client",
+	}}
+
+	assert.NoError(t, checkSubject(cert, ClientCommonName))
+
+	err := checkSubject(cert, ServerCommonName)
+	assert.True(t, errors.Is(err, ErrInvalidCertificate), "expected ErrInvalidCertificate, got %v", err)
+}
@@ -0,0 +1,258 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// cgroupControllers are the cgroup v2 controllers the manager uses to
+// limit the resources a job can use.
+var cgroupControllers = []string{"cpu", "io", "memory", "pids"}
+
+// cgroupWrite is a value to write to a cgroup control file.
+type cgroupWrite struct {
+	file, value string
+}
+
+// controlWrites returns the values to write to the cgroup control
+// files to apply the limits.
+func (l Limits) controlWrites() []cgroupWrite {
+	var writes []cgroupWrite
+
+	if l.MaxPids > 0 {
+		writes = append(writes, cgroupWrite{"pids.max", strconv.FormatInt(l.MaxPids, 10)})
+	}
+	if l.CPUQuota > 0 {
+		max := strconv.FormatInt(l.CPUQuota.Microseconds(), 10)
+		if l.CPUPeriod > 0 {
+			max += " " + strconv.FormatInt(l.CPUPeriod.Microseconds(), 10)
+		}
+		writes = append(writes, cgroupWrite{"cpu.max", max})
+	}
+	if l.MemoryBytes > 0 {
+		writes = append(writes, cgroupWrite{"memory.max", strconv.FormatInt(l.MemoryBytes, 10)})
+	}
+	// io.max only accepts the limits for one device per write
+	for _, limit := range l.IO {
+		line := limit.Device
+		if limit.ReadBPS > 0 {
+			line += " rbps=" + strconv.FormatInt(limit.ReadBPS, 10)
+		}
+		if limit.WriteBPS > 0 {
+			line += " wbps=" + strconv.FormatInt(limit.WriteBPS, 10)
+		}
+		writes = append(writes, cgroupWrite{"io.max", line})
+	}
+
+	return writes
+}
+
+// WithCgroup sets the cgroup v2 directory ( for example
+// /sys/fs/cgroup/workernator ) that the cgroup for each job is created
+// under. The directory is created if it doesn't exist, and the cpu, io,
+// memory, and pids controllers are enabled for its children. If this
+// option isn't provided, no resource limits are applied to jobs.
+func WithCgroup(parent string) Option {
+	return func(m *Manager) error {
+		if err := setupCgroupParent(parent); err != nil {
+			return err
+		}
+		m.cgroupParent = parent
+		return nil
+	}
+}
+
+// setupCgroupParent creates the parent cgroup, and enables the
+// controllers in cgroupControllers that are available to it.
+func setupCgroupParent(parent string) error {
+	if err := os.MkdirAll(parent, 0o755); err != nil { //nolint:gosec // cgroup directories are world readable
+		return fmt.Errorf("unable to create cgroup '%v': %w", parent, err)
+	}
+
+	available, err := os.ReadFile(filepath.Join(parent, "cgroup.controllers"))
+	if err != nil {
+		return fmt.Errorf("unable to read available controllers for cgroup '%v': %w", parent, err)
+	}
+
+	var enable []string
+	for _, c := range strings.Fields(string(available)) {
+		for _, want := range cgroupControllers {
+			if c == want {
+				enable = append(enable, "+"+c)
+			}
+		}
+	}
+	if len(enable) == 0 {
+		return nil
+	}
+
+	err = os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte(strings.Join(enable, " ")), 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to enable controllers for cgroup '%v': %w", parent, err)
+	}
+	return nil
+}
+
+// cgroup is the cgroup v2 group a single job runs in.
+type cgroup struct {
+	path string
+}
+
+// newCgroup creates the cgroup name under parent, and applies limits.
+func newCgroup(parent, name string, limits Limits) (*cgroup, error) {
+	cg := &cgroup{path: filepath.Join(parent, name)}
+	err := os.Mkdir(cg.path, 0o755) //nolint:gosec // cgroup directories are world readable
+	if err != nil && !errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("unable to create cgroup '%v': %w", cg.path, err)
+	}
+
+	for _, w := range limits.controlWrites() {
+		if err := cg.write(w.file, w.value); err != nil {
+			_ = cg.remove()
+			return nil, err
+		}
+	}
+
+	return cg, nil
+}
+
+// addProcess moves the process with the given PID into the cgroup.
+func (cg *cgroup) addProcess(pid int) error {
+	return cg.write("cgroup.procs", strconv.Itoa(pid))
+}
+
+// kill sends SIGKILL to every process in the cgroup. cgroup.kill was
+// added in Linux 5.14, so a missing file isn't an error.
+func (cg *cgroup) kill() error {
+	err := cg.write("cgroup.kill", "1")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// remove deletes the cgroup. A cgroup can only be removed once every
+// process in it has exited, so this retries for a short while in case
+// the kernel is still cleaning up.
+func (cg *cgroup) remove() error {
+	var err error
+	for i := 0; i < 50; i++ {
+		err = os.Remove(cg.path)
+		if err == nil || errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if !errors.Is(err, syscall.EBUSY) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return fmt.Errorf("unable to remove cgroup '%v': %w", cg.path, err)
+}
+
+// usage updates u with the memory and IO usage of the cgroup, which
+// covers every process in the job rather than only the ones the job
+// command waited for. Anything the kernel doesn't report is left as it
+// is; memory.peak was only added in Linux 5.19, and io.stat needs the
+// io controller.
+func (cg *cgroup) usage(u *ResourceUsage) {
+	if peak, err := cg.read("memory.peak"); err == nil {
+		if v, err := strconv.ParseInt(strings.TrimSpace(peak), 10, 64); err == nil {
+			u.PeakMemoryBytes = v
+		}
+	}
+
+	stat, err := cg.read("io.stat")
+	if err != nil {
+		return
+	}
+	var read, written int64
+	for _, line := range strings.Split(stat, "\n") {
+		// each line is the device followed by key=value pairs, ie
+		// "8:0 rbytes=1024 wbytes=0 rios=1 wios=0 dbytes=0 dios=0"
+		for _, field := range strings.Fields(line) {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch key {
+			case "rbytes":
+				read += v
+			case "wbytes":
+				written += v
+			}
+		}
+	}
+	u.IOReadBytes = read
+	u.IOWriteBytes = written
+}
+
+// cgroupEvents are the counts of limit related events for a cgroup.
+type cgroupEvents struct {
+	// oomKills is how many processes were killed by the OOM killer.
+	oomKills int64
+	// pidsMax is how many times a fork failed because of pids.max.
+	pidsMax int64
+}
+
+// events reads memory.events and pids.events. Counts for controllers
+// that aren't enabled are left as zero.
+func (cg *cgroup) events() cgroupEvents {
+	var events cgroupEvents
+	if data, err := cg.read("memory.events"); err == nil {
+		events.oomKills = flatKeyed(data)["oom_kill"]
+	}
+	if data, err := cg.read("pids.events"); err == nil {
+		events.pidsMax = flatKeyed(data)["max"]
+	}
+	return events
+}
+
+// flatKeyed parses the contents of a cgroup file where each line is a
+// key and a number, ie "oom_kill 1".
+func flatKeyed(data string) map[string]int64 {
+	values := map[string]int64{}
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if v, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			values[fields[0]] = v
+		}
+	}
+	return values
+}
+
+func (cg *cgroup) read(file string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(cg.path, file))
+	if err != nil {
+		return "", fmt.Errorf("unable to read cgroup file '%v': %w", file, err)
+	}
+	return string(data), nil
+}
+
+func (cg *cgroup) write(file, value string) error {
+	// cgroup control files already exist, so O_CREATE is never needed
+	f, err := os.OpenFile(filepath.Join(cg.path, file), os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return fmt.Errorf("unable to open cgroup file '%v': %w", file, err)
+	}
+	if _, err := f.WriteString(value); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to write '%v' to cgroup file '%v': %w", value, file, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unable to write '%v' to cgroup file '%v': %w", value, file, err)
+	}
+	return nil
+}
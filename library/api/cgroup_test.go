@@ -0,0 +1,246 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimits_ControlWrites(t *testing.T) {
+	limits := Limits{
+		MaxPids:     10,
+		CPUQuota:    200 * time.Millisecond,
+		CPUPeriod:   time.Second,
+		MemoryBytes: 10485760,
+		IO: []IOLimit{
+			{Device: "8:0", ReadBPS: 10485760, WriteBPS: 10485760},
+			{Device: "8:16", WriteBPS: 1024},
+		},
+	}
+
+	expect := []cgroupWrite{
+		{"pids.max", "10"},
+		{"cpu.max", "200000 1000000"},
+		{"memory.max", "10485760"},
+		{"io.max", "8:0 rbps=10485760 wbps=10485760"},
+		{"io.max", "8:16 wbps=1024"},
+	}
+	assert.Equal(t, expect, limits.controlWrites())
+
+	assert.Empty(t, Limits{}.controlWrites())
+}
+
+// fakeCgroup creates a directory that looks enough like a cgroup to
+// test writing the control files.
+func fakeCgroup(t *testing.T, parent, name string) string {
+	t.Helper()
+	path := filepath.Join(parent, name)
+	require.NoError(t, os.MkdirAll(path, 0o755))
+	for _, f := range []string{"cgroup.procs", "pids.max", "cpu.max", "memory.max", "io.max"} {
+		require.NoError(t, os.WriteFile(filepath.Join(path, f), nil, 0o644))
+	}
+	return path
+}
+
+func TestNewCgroup(t *testing.T) {
+	parent := t.TempDir()
+	path := fakeCgroup(t, parent, "job")
+
+	cg, err := newCgroup(parent, "job", DefaultLimits)
+	require.NoError(t, err)
+	require.NoError(t, cg.addProcess(1234))
+
+	for file, expect := range map[string]string{
+		"pids.max":     "10",
+		"cpu.max":      "200000 1000000",
+		"memory.max":   "10485760",
+		"cgroup.procs": "1234",
+	} {
+		got, err := os.ReadFile(filepath.Join(path, file))
+		require.NoError(t, err)
+		assert.Equal(t, expect, string(got), "unexpected value in '%v'", file)
+	}
+}
+
+func TestNewCgroupMissingController(t *testing.T) {
+	parent := t.TempDir()
+
+	// without the controller files, the limits can't be applied
+	_, err := newCgroup(parent, "job", DefaultLimits)
+	assert.Error(t, err)
+
+	_, err = os.Stat(filepath.Join(parent, "job"))
+	assert.True(t, os.IsNotExist(err), "expected cgroup to be cleaned up, got %v", err)
+}
+
+func TestCgroupKill(t *testing.T) {
+	parent := t.TempDir()
+	path := fakeCgroup(t, parent, "job")
+	cg := &cgroup{path: path}
+
+	// cgroup.kill only exists on Linux 5.14 and later
+	assert.NoError(t, cg.kill())
+
+	require.NoError(t, os.WriteFile(filepath.Join(path, "cgroup.kill"), nil, 0o644))
+	require.NoError(t, cg.kill())
+	got, err := os.ReadFile(filepath.Join(path, "cgroup.kill"))
+	require.NoError(t, err)
+	assert.Equal(t, "1", string(got))
+}
+
+func TestCgroupUsage(t *testing.T) {
+	path := fakeCgroup(t, t.TempDir(), "job")
+	cg := &cgroup{path: path}
+
+	// without the files, the usage from the process is kept
+	usage := ResourceUsage{PeakMemoryBytes: 1, IOReadBytes: 2, IOWriteBytes: 3}
+	cg.usage(&usage)
+	assert.Equal(t, ResourceUsage{PeakMemoryBytes: 1, IOReadBytes: 2, IOWriteBytes: 3}, usage)
+
+	require.NoError(t, os.WriteFile(filepath.Join(path, "memory.peak"), []byte("4096\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "io.stat"), []byte(
+		"8:0 rbytes=1024 wbytes=512 rios=2 wios=1 dbytes=0 dios=0\n"+
+			"8:16 rbytes=100 wbytes=10 rios=1 wios=1 dbytes=0 dios=0\n",
+	), 0o644))
+
+	cg.usage(&usage)
+	assert.Equal(t, ResourceUsage{PeakMemoryBytes: 4096, IOReadBytes: 1124, IOWriteBytes: 522}, usage)
+}
+
+func TestCgroupEvents(t *testing.T) {
+	path := fakeCgroup(t, t.TempDir(), "job")
+	cg := &cgroup{path: path}
+
+	// without the controllers there are no events
+	assert.Equal(t, cgroupEvents{}, cg.events())
+
+	require.NoError(t, os.WriteFile(filepath.Join(path, "memory.events"), []byte(
+		"low 0\nhigh 0\nmax 12\noom 1\noom_kill 1\noom_group_kill 0\n",
+	), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(path, "pids.events"), []byte("max 3\n"), 0o644))
+
+	assert.Equal(t, cgroupEvents{oomKills: 1, pidsMax: 3}, cg.events())
+}
+
+// cgroup2Mount returns where the cgroup v2 hierarchy is mounted, or
+// skips the test if it isn't.
+func cgroup2Mount(t *testing.T) string {
+	t.Helper()
+	f, err := os.Open("/proc/self/mounts")
+	require.NoError(t, err)
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) > 2 && fields[2] == "cgroup2" {
+			return fields[1]
+		}
+	}
+	t.Skip("cgroup v2 isn't mounted")
+	return ""
+}
+
+// testCgroupParent creates a cgroup for the test to create job cgroups
+// under, skipping the test if it can't.
+func testCgroupParent(t *testing.T) string {
+	t.Helper()
+	parent := filepath.Join(cgroup2Mount(t), "workernator-test-"+strconv.Itoa(os.Getpid())+"-"+t.Name())
+	if err := os.Mkdir(parent, 0o755); err != nil {
+		t.Skipf("unable to create test cgroup: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Remove(parent) })
+	return parent
+}
+
+// requireController skips the test if the controller isn't available
+// to cgroups created under parent.
+func requireController(t *testing.T, parent, controller string) {
+	t.Helper()
+	available, err := os.ReadFile(filepath.Join(parent, "cgroup.controllers"))
+	require.NoError(t, err)
+	for _, c := range strings.Fields(string(available)) {
+		if c == controller {
+			return
+		}
+	}
+	t.Skipf("the %v controller isn't available", controller)
+}
+
+func TestManager_Cgroup(t *testing.T) {
+	parent := testCgroupParent(t)
+
+	// only apply limits for the controllers this system has available
+	var limits Limits
+	available, err := os.ReadFile(filepath.Join(parent, "cgroup.controllers"))
+	require.NoError(t, err)
+	if strings.Contains(string(available), "pids") {
+		limits.MaxPids = 5
+	}
+
+	m, err := NewManager(WithWorkDir(t.TempDir()), WithCgroup(parent), WithDefaultLimits(limits))
+	require.NoError(t, err)
+
+	info, err := m.StartJob("sleep", []string{"30"})
+	require.NoError(t, err)
+	assert.Equal(t, limits, info.Limits)
+
+	m.mu.RLock()
+	j := m.jobs[info.ID]
+	m.mu.RUnlock()
+
+	procs, err := os.ReadFile(filepath.Join(j.cgroup.path, "cgroup.procs"))
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(j.cmd.Process.Pid)+"\n", string(procs))
+
+	if limits.MaxPids > 0 {
+		max, err := os.ReadFile(filepath.Join(j.cgroup.path, "pids.max"))
+		require.NoError(t, err)
+		assert.Equal(t, "5\n", string(max))
+	}
+
+	_, err = m.StopJob(context.Background(), info.ID)
+	require.NoError(t, err)
+
+	_, err = os.Stat(j.cgroup.path)
+	assert.True(t, os.IsNotExist(err), "expected cgroup to be removed, got %v", err)
+}
+
+func TestManager_CgroupOOMKilled(t *testing.T) {
+	parent := testCgroupParent(t)
+	requireController(t, parent, "memory")
+
+	m, err := NewManager(WithWorkDir(t.TempDir()), WithCgroup(parent), WithDefaultLimits(Limits{MemoryBytes: 10 * 1024 * 1024}))
+	require.NoError(t, err)
+
+	// holds 100MiB in a shell variable, which is well over the limit
+	info, err := m.StartJob("sh", []string{"-c", `x=$(head -c 104857600 /dev/zero | tr '\0' a); echo ${#x}`})
+	require.NoError(t, err)
+
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, StatusFailed, info.Status)
+	assert.Equal(t, ReasonOOMKilled, info.Reason)
+}
+
+func TestManager_CgroupPidsExhausted(t *testing.T) {
+	parent := testCgroupParent(t)
+	requireController(t, parent, "pids")
+
+	m, err := NewManager(WithWorkDir(t.TempDir()), WithCgroup(parent), WithDefaultLimits(Limits{MaxPids: 3}))
+	require.NoError(t, err)
+
+	info, err := m.StartJob("sh", []string{"-e", "-c", `for i in 1 2 3 4 5 6; do sleep 1 & done; wait`})
+	require.NoError(t, err)
+
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, StatusFailed, info.Status)
+	assert.Equal(t, ReasonPidsExhausted, info.Reason)
+}
@@ -0,0 +1,37 @@
+package api
+
+import "errors"
+
+// ErrJobNotFound is returned when the ID provided doesn't map to any
+// job the manager knows about.
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrInvalidCommand is returned by StartJob when the command is
+// missing or can't be run.
+var ErrInvalidCommand = errors.New("invalid command")
+
+// ErrInvalidLimits is returned by StartJob when the resource limits for
+// a job are invalid, or are higher than the manager allows.
+var ErrInvalidLimits = errors.New("invalid resource limits")
+
+// ErrInvalidTail is returned by TailJob when the options used to
+// choose where to start reading from are invalid.
+var ErrInvalidTail = errors.New("invalid tail options")
+
+// ErrNoInput is returned by SendInput when the job isn't accepting
+// input, either because it wasn't started with stdin, stdin has been
+// closed, or input is already being sent.
+var ErrNoInput = errors.New("job isn't accepting input")
+
+// ErrNoTerminal is returned by ResizeTerminal when the job wasn't
+// started using WithTTY, or has ended.
+var ErrNoTerminal = errors.New("job doesn't have a terminal")
+
+// ErrManagerClosed is returned by StartJob and Subscribe once Shutdown
+// has been called.
+var ErrManagerClosed = errors.New("manager is shut down")
+
+// ErrSubscriberTooSlow is returned by Subscription.Err when the
+// subscription was ended because events weren't being read quickly
+// enough.
+var ErrSubscriberTooSlow = errors.New("subscriber fell too far behind")
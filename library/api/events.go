@@ -0,0 +1,198 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// eventBufferSize is how many events a subscription can fall behind by
+// before it's closed with ErrSubscriberTooSlow.
+const eventBufferSize = 256
+
+// EventType describes what happened to a job.
+type EventType int
+
+const (
+	// EventCreated is sent once a job has been added, just before
+	// EventStarted. The job is as it was just before its command was
+	// started, so has StatusUnknown. Jobs that couldn't be started are
+	// never added, so have no events sent.
+	EventCreated EventType = iota + 1
+	// EventStarted is sent once the job command is running.
+	EventStarted
+	// EventStopped is sent when a job ends after being stopped using
+	// StopJob or Shutdown.
+	EventStopped
+	// EventTimedOut is sent when a job ends after running for longer than
+	// its max runtime.
+	EventTimedOut
+	// EventFinished is sent when a job ends successfully.
+	EventFinished
+	// EventFailed is sent when a job ends unsuccessfully.
+	EventFailed
+)
+
+// String returns a human-readable version of the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventCreated:
+		return "Created"
+	case EventStarted:
+		return "Started"
+	case EventStopped:
+		return "Stopped"
+	case EventTimedOut:
+		return "TimedOut"
+	case EventFinished:
+		return "Finished"
+	case EventFailed:
+		return "Failed"
+	}
+	return "Unknown"
+}
+
+// endEvents maps the status of a job that has ended to the event sent
+// when it ends.
+var endEvents = map[JobStatus]EventType{
+	StatusStopped:  EventStopped,
+	StatusTimedOut: EventTimedOut,
+	StatusFinished: EventFinished,
+	StatusFailed:   EventFailed,
+}
+
+// Event is something that happened to a job.
+type Event struct {
+	Type EventType
+	// Job is a snapshot of the job taken just after the event happened.
+	Job *JobInfo
+	At  time.Time
+}
+
+// Subscription receives the events for every job the manager starts.
+type Subscription struct {
+	events chan Event
+
+	mu  sync.Mutex
+	err error
+}
+
+// Events returns the channel events are sent on. The channel is closed
+// once the subscription has ended, after which Err returns why.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err returns why the subscription ended, or nil if it hasn't. It
+// returns the error from the context passed to Subscribe if it was
+// cancelled, ErrSubscriberTooSlow if the events weren't being read
+// quickly enough, or ErrManagerClosed once Shutdown has been called.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Subscribe returns a subscription to the events for every job. Events
+// for jobs that were started before Subscribe was called aren't sent.
+// The subscription ends when ctx is cancelled, or once Shutdown has been
+// called and every job has been stopped.
+func (m *Manager) Subscribe(ctx context.Context) (*Subscription, error) {
+	s := &Subscription{events: make(chan Event, eventBufferSize)}
+	if !m.events.add(s) {
+		return nil, ErrManagerClosed
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			m.events.remove(s, ctx.Err())
+		case <-m.events.closed:
+		}
+	}()
+	return s, nil
+}
+
+// eventBus sends job events to every subscription.
+type eventBus struct {
+	// closed is closed once the bus has been closed, so that
+	// subscriptions stop waiting for their context to be cancelled.
+	closed chan struct{}
+
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+	// done is set once the bus has been closed.
+	done bool
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{
+		closed: make(chan struct{}),
+		subs:   map[*Subscription]struct{}{},
+	}
+}
+
+// add adds a subscription, returning false if the bus has been closed.
+func (b *eventBus) add(s *Subscription) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.done {
+		return false
+	}
+	b.subs[s] = struct{}{}
+	return true
+}
+
+// publish sends an event to every subscription. Subscriptions that
+// can't keep up are ended rather than holding up everyone else.
+func (b *eventBus) publish(t EventType, info *JobInfo) {
+	ev := Event{Type: t, Job: info, At: time.Now()}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for s := range b.subs {
+		select {
+		case s.events <- ev:
+		default:
+			b.end(s, ErrSubscriberTooSlow)
+		}
+	}
+}
+
+// remove ends a subscription, if it hasn't already ended.
+func (b *eventBus) remove(s *Subscription, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[s]; ok {
+		b.end(s, err)
+	}
+}
+
+// close ends every subscription, and stops any more being added.
+func (b *eventBus) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.done {
+		return
+	}
+	b.done = true
+	close(b.closed)
+	for s := range b.subs {
+		b.end(s, ErrManagerClosed)
+	}
+}
+
+// end records why a subscription ended and closes its channel. Events
+// are only sent while b.mu is held, so b.mu must be held to call end.
+func (b *eventBus) end(s *Subscription, err error) {
+	delete(b.subs, s)
+
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	close(s.events)
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nextEvent waits for the next event from the subscription.
+func nextEvent(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case ev, ok := <-sub.Events():
+		require.True(t, ok, "subscription ended: %v", sub.Err())
+		return ev
+	case <-time.After(15 * time.Second):
+		require.FailNow(t, "timed out waiting for event")
+	}
+	return Event{}
+}
+
+func TestManager_Subscribe(t *testing.T) {
+	m := newTestManager(t)
+
+	sub, err := m.Subscribe(context.Background())
+	require.NoError(t, err)
+
+	info, err := m.StartJob("true", nil)
+	require.NoError(t, err)
+
+	ev := nextEvent(t, sub)
+	assert.Equal(t, EventCreated, ev.Type)
+	assert.Equal(t, info.ID, ev.Job.ID)
+	assert.Equal(t, StatusUnknown, ev.Job.Status)
+	assert.False(t, ev.At.IsZero())
+
+	ev = nextEvent(t, sub)
+	assert.Equal(t, EventStarted, ev.Type)
+	assert.Equal(t, StatusRunning, ev.Job.Status)
+
+	ev = nextEvent(t, sub)
+	assert.Equal(t, EventFinished, ev.Type)
+	assert.Equal(t, StatusFinished, ev.Job.Status)
+	assert.False(t, ev.Job.EndedAt.IsZero())
+}
+
+func TestManager_SubscribeEndEvents(t *testing.T) {
+	m := newTestManager(t)
+
+	sub, err := m.Subscribe(context.Background())
+	require.NoError(t, err)
+
+	// events for different jobs are only ordered within each job, so
+	// only the end events are checked
+	ends := func(n int) map[string]EventType {
+		t.Helper()
+		out := map[string]EventType{}
+		for len(out) < n {
+			ev := nextEvent(t, sub)
+			if ev.Type != EventCreated && ev.Type != EventStarted {
+				out[ev.Job.ID] = ev.Type
+			}
+		}
+		return out
+	}
+
+	failed, err := m.StartJob("false", nil)
+	require.NoError(t, err)
+	stopped, err := m.StartJob("sleep", []string{"30"})
+	require.NoError(t, err)
+	_, err = m.StopJob(context.Background(), stopped.ID)
+	require.NoError(t, err)
+	timedOut, err := m.StartJob("sh", []string{"-c", `trap 'exit 0' TERM; while true; do sleep 0.1; done`},
+		WithMaxRuntime(100*time.Millisecond))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]EventType{
+		failed.ID:   EventFailed,
+		stopped.ID:  EventStopped,
+		timedOut.ID: EventTimedOut,
+	}, ends(3))
+
+	// a job that can't be started is never added, so nothing is sent
+	// about it; the next events are for the job started after it
+	_, err = m.StartJob("not-a-real-command-workernator", nil)
+	require.Error(t, err)
+	next, err := m.StartJob("true", nil)
+	require.NoError(t, err)
+	ev := nextEvent(t, sub)
+	assert.Equal(t, EventCreated, ev.Type)
+	assert.Equal(t, next.ID, ev.Job.ID)
+	assert.Equal(t, StatusUnknown, ev.Job.Status)
+}
+
+func TestManager_SubscribeCancel(t *testing.T) {
+	m := newTestManager(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub, err := m.Subscribe(ctx)
+	require.NoError(t, err)
+	cancel()
+
+	select {
+	case _, ok := <-sub.Events():
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "subscription wasn't ended")
+	}
+	assert.True(t, errors.Is(sub.Err(), context.Canceled), "expected context.Canceled, got %v", sub.Err())
+}
+
+func TestManager_SubscribeShutdown(t *testing.T) {
+	m := newTestManager(t)
+
+	sub, err := m.Subscribe(context.Background())
+	require.NoError(t, err)
+	info, err := m.StartJob("sleep", []string{"30"})
+	require.NoError(t, err)
+
+	require.NoError(t, m.Shutdown())
+
+	// the job being stopped is sent before the subscription ends
+	var types []EventType
+	for ev := range sub.Events() {
+		assert.Equal(t, info.ID, ev.Job.ID)
+		types = append(types, ev.Type)
+	}
+	assert.Equal(t, []EventType{EventCreated, EventStarted, EventStopped}, types)
+	assert.True(t, errors.Is(sub.Err(), ErrManagerClosed), "expected ErrManagerClosed, got %v", sub.Err())
+
+	_, err = m.Subscribe(context.Background())
+	assert.True(t, errors.Is(err, ErrManagerClosed), "expected ErrManagerClosed, got %v", err)
+}
+
+func TestEventBus_TooSlow(t *testing.T) {
+	bus := newEventBus()
+	slow := &Subscription{events: make(chan Event, eventBufferSize)}
+	require.True(t, bus.add(slow))
+
+	for i := 0; i <= eventBufferSize; i++ {
+		bus.publish(EventStarted, &JobInfo{})
+	}
+
+	n := 0
+	for range slow.Events() {
+		n++
+	}
+	assert.Equal(t, eventBufferSize, n)
+	assert.True(t, errors.Is(slow.Err(), ErrSubscriberTooSlow), "expected ErrSubscriberTooSlow, got %v", slow.Err())
+
+	// publishing carries on without the subscription
+	bus.publish(EventStarted, &JobInfo{})
+}
@@ -0,0 +1,336 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+)
+
+const (
+	// initArg is used as argv[0] when the manager re-executes the
+	// current binary, and is how Init knows it's running as the init
+	// process of a job.
+	initArg = "workernator-init"
+
+	// initSelfExe is the binary the manager re-executes to start the
+	// init process of a job.
+	initSelfExe = "/proc/self/exe"
+
+	// initJobIDEnv is the environment variable used to pass the job ID
+	// to the init process.
+	initJobIDEnv = "WORKERNATOR_JOB_ID"
+
+	// initRootFSEnv is the environment variable used to pass the path
+	// of the unpacked root filesystem to the init process.
+	initRootFSEnv = "WORKERNATOR_ROOTFS"
+
+	// initTermEnv is the environment variable used to pass the type of
+	// terminal the job has, if it has one, to the init process.
+	initTermEnv = "WORKERNATOR_TERM"
+
+	// initErrorFd is the file descriptor the init process uses to
+	// report a failure back to the manager. It's closed on exec, so
+	// the manager knows the job has started once it reads EOF.
+	initErrorFd = 3
+
+	// initStartFd is the file descriptor the init process reads from
+	// before doing anything else. The manager closes the other end once
+	// it has finished setting up the job ( ie, moving it into a cgroup ).
+	initStartFd = 4
+
+	// initFailedExitCode is the exit code used by the init process if
+	// it's unable to set up or exec the job.
+	initFailedExitCode = 127
+
+	// initCommandFailed and initSetupFailed are written to the error
+	// pipe before the error message, so the manager can tell a bad job
+	// command apart from a failure setting up the job environment.
+	initCommandFailed = 'C'
+	initSetupFailed   = 'S'
+)
+
+// commandError is returned by runInit when the job command can't be
+// found or exec'd.
+type commandError struct{ err error }
+
+func (e commandError) Error() string { return e.err.Error() }
+func (e commandError) Unwrap() error { return e.err }
+
+// nobodyID is the user and group ID on the host that root inside a job
+// maps to when the manager is run as root, unless WithJobIDs is used.
+const nobodyID = 65534
+
+// WithJobIDs sets the user and group IDs on the host that root inside
+// each job maps to. Neither can be zero, as root inside a job would then
+// have the same access to the host as root does. The manager must be run
+// as root to use IDs other than its own.
+//
+// By default the user and group running the manager are used, unless
+// that's root, in which case jobs run as nobody ( 65534 ).
+func WithJobIDs(uid, gid int) Option {
+	return func(m *Manager) error {
+		if uid <= 0 || gid <= 0 {
+			return fmt.Errorf("job user and group IDs must be more than zero, got %v:%v", uid, gid)
+		}
+		m.jobUID, m.jobGID = uid, gid
+		return nil
+	}
+}
+
+// defaultJobID returns the ID root inside a job maps to if WithJobIDs
+// isn't used, given the ID running the manager.
+func defaultJobID(id int) int {
+	if id == 0 {
+		return nobodyID
+	}
+	return id
+}
+
+// checkSearchable returns an error unless every directory leading to
+// and including dir can be searched by the given user and group, so
+// that jobs running as them can reach the root filesystems unpacked
+// inside it.
+func checkSearchable(dir string, uid, gid int) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("unable to find absolute path of '%v': %w", dir, err)
+	}
+
+	for path := abs; ; path = filepath.Dir(path) {
+		st, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		perm := st.Mode().Perm()
+		search := perm&0o001 != 0
+		if sys, ok := st.Sys().(*syscall.Stat_t); ok {
+			switch {
+			case int(sys.Uid) == uid:
+				search = perm&0o100 != 0
+			case int(sys.Gid) == gid:
+				search = perm&0o010 != 0
+			}
+		}
+		if !search {
+			return fmt.Errorf("'%v' can't be searched by jobs running as %v:%v", path, uid, gid)
+		}
+
+		if path == filepath.Dir(path) {
+			return nil
+		}
+	}
+}
+
+// jobPath is the PATH used when looking up and running the job command.
+const jobPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// Init must be called at the very start of main() in any binary that
+// uses a Manager, before any other setup is done.
+//
+// The manager starts jobs by re-executing the current binary through
+// /proc/self/exe inside a set of new namespaces. When the binary has
+// been started that way, Init configures the job environment and then
+// replaces the process with the requested job command, so it never
+// returns. Otherwise Init returns immediately and does nothing.
+func Init() {
+	if os.Args[0] != initArg {
+		return
+	}
+
+	errPipe := os.NewFile(initErrorFd, "init-error")
+	syscall.CloseOnExec(initErrorFd)
+
+	// wait for the manager to finish setting up the job
+	startPipe := os.NewFile(initStartFd, "init-start")
+	_, err := io.Copy(io.Discard, startPipe)
+	_ = startPipe.Close()
+	if err == nil {
+		err = runInit(os.Args[1:])
+	}
+	// runInit only returns if something went wrong
+	kind := initSetupFailed
+	var cmdErr commandError
+	if errors.As(err, &cmdErr) {
+		kind = initCommandFailed
+	}
+	_, _ = fmt.Fprintf(errPipe, "%c%v", kind, err)
+	os.Exit(initFailedExitCode)
+}
+
+// runInit sets up the job environment then execs the job command.
+func runInit(args []string) error {
+	if len(args) == 0 {
+		return commandError{errors.New("no command provided")}
+	}
+
+	id := os.Getenv(initJobIDEnv)
+	if err := syscall.Sethostname([]byte(id)); err != nil {
+		return fmt.Errorf("unable to set hostname: %w", err)
+	}
+
+	if rootfs := os.Getenv(initRootFSEnv); rootfs != "" {
+		if err := setupRootFS(rootfs); err != nil {
+			return err
+		}
+	}
+
+	env := []string{
+		"PATH=" + jobPath,
+		"HOME=/",
+		"HOSTNAME=" + id,
+	}
+	if term := os.Getenv(initTermEnv); term != "" {
+		env = append(env, "TERM="+term)
+	}
+	if err := os.Setenv("PATH", jobPath); err != nil {
+		return fmt.Errorf("unable to set PATH: %w", err)
+	}
+
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return commandError{fmt.Errorf("unable to find '%v': %w", args[0], err)}
+	}
+
+	if err := syscall.Exec(path, args, env); err != nil {
+		return commandError{fmt.Errorf("unable to exec '%v': %w", path, err)}
+	}
+	return nil
+}
+
+// jobDevices are the devices bind mounted from the host into the root
+// filesystem of a job.
+var jobDevices = []string{"/dev/null", "/dev/zero", "/dev/random", "/dev/urandom", "/dev/tty"}
+
+// setupRootFS mounts /proc, a private /tmp, a private /dev/pts, and the
+// devices in jobDevices into the root filesystem, then uses pivot_root
+// to make it the root of the mount namespace.
+func setupRootFS(rootfs string) error {
+	// make sure none of the mounts below propagate back to the host
+	if err := syscall.Mount("", "/", "", syscall.MS_PRIVATE|syscall.MS_REC, ""); err != nil {
+		return fmt.Errorf("unable to make mounts private: %w", err)
+	}
+
+	// pivot_root requires the new root to be a mount point
+	if err := syscall.Mount(rootfs, rootfs, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return fmt.Errorf("unable to bind mount root filesystem: %w", err)
+	}
+
+	mounts := []struct {
+		source, target, fstype string
+		flags                  uintptr
+		data                   string
+	}{
+		{"proc", "proc", "proc", syscall.MS_NOSUID | syscall.MS_NODEV | syscall.MS_NOEXEC, ""},
+		{"tmpfs", "tmp", "tmpfs", syscall.MS_NOSUID | syscall.MS_NODEV, "mode=1777"},
+	}
+	for _, mnt := range mounts {
+		target := filepath.Join(rootfs, mnt.target)
+		if err := os.MkdirAll(target, 0o755); err != nil { //nolint:gosec // these need to be readable by the job
+			return fmt.Errorf("unable to create '/%v': %w", mnt.target, err)
+		}
+		if err := syscall.Mount(mnt.source, target, mnt.fstype, mnt.flags, mnt.data); err != nil {
+			return fmt.Errorf("unable to mount '/%v': %w", mnt.target, err)
+		}
+	}
+
+	for _, dev := range jobDevices {
+		target := filepath.Join(rootfs, dev)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil { //nolint:gosec // these need to be readable by the job
+			return fmt.Errorf("unable to create '%v': %w", filepath.Dir(dev), err)
+		}
+		f, err := os.OpenFile(target, os.O_CREATE, 0o666)
+		if err != nil {
+			return fmt.Errorf("unable to create '%v': %w", dev, err)
+		}
+		_ = f.Close()
+		if err := syscall.Mount(dev, target, "", syscall.MS_BIND, ""); err != nil {
+			return fmt.Errorf("unable to mount '%v': %w", dev, err)
+		}
+	}
+
+	if err := setupDevPts(rootfs); err != nil {
+		return err
+	}
+
+	oldRoot := filepath.Join(rootfs, ".old_root")
+	if err := os.MkdirAll(oldRoot, 0o700); err != nil {
+		return fmt.Errorf("unable to create directory for old root: %w", err)
+	}
+	if err := syscall.PivotRoot(rootfs, oldRoot); err != nil {
+		return fmt.Errorf("unable to pivot root: %w", err)
+	}
+	if err := os.Chdir("/"); err != nil {
+		return fmt.Errorf("unable to change to new root: %w", err)
+	}
+
+	if err := syscall.Unmount("/.old_root", syscall.MNT_DETACH); err != nil {
+		return fmt.Errorf("unable to unmount old root: %w", err)
+	}
+	if err := os.Remove("/.old_root"); err != nil {
+		return fmt.Errorf("unable to remove old root: %w", err)
+	}
+	return nil
+}
+
+// setupDevPts mounts a new devpts instance at /dev/pts in the root
+// filesystem, with /dev/ptmx pointing at it, so any terminals the job
+// opens are its own and it can't see the terminals on the host.
+func setupDevPts(rootfs string) error {
+	target := filepath.Join(rootfs, "dev", "pts")
+	if err := os.MkdirAll(target, 0o755); err != nil { //nolint:gosec // this needs to be readable by the job
+		return fmt.Errorf("unable to create '/dev/pts': %w", err)
+	}
+	if err := syscall.Mount("devpts", target, "devpts", syscall.MS_NOSUID|syscall.MS_NOEXEC, "newinstance,ptmxmode=0666,mode=0620"); err != nil {
+		return fmt.Errorf("unable to mount '/dev/pts': %w", err)
+	}
+
+	ptmx := filepath.Join(rootfs, "dev", "ptmx")
+	if err := os.Remove(ptmx); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("unable to remove '/dev/ptmx': %w", err)
+	}
+	if err := os.Symlink("pts/ptmx", ptmx); err != nil {
+		return fmt.Errorf("unable to create '/dev/ptmx': %w", err)
+	}
+	return nil
+}
+
+// initCommand builds the command used to launch the init process for
+// a job, which runs inside new UTS, PID, mount, network, and user
+// namespaces. The user namespace maps root inside the job to uid and gid
+// on the host. If rootfs isn't empty, the job is run inside the root
+// filesystem unpacked there.
+func initCommand(id, rootfs, command string, args []string, uid, gid int) *exec.Cmd {
+	cmd := &exec.Cmd{
+		Path: initSelfExe,
+		Args: append([]string{initArg, command}, args...),
+		Env:  []string{initJobIDEnv + "=" + id},
+	}
+	if rootfs != "" {
+		cmd.Env = append(cmd.Env, initRootFSEnv+"="+rootfs)
+	}
+
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWUTS |
+			syscall.CLONE_NEWPID |
+			syscall.CLONE_NEWNS |
+			syscall.CLONE_NEWNET |
+			syscall.CLONE_NEWUSER,
+		UidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: uid, Size: 1},
+		},
+		GidMappings: []syscall.SysProcIDMap{
+			{ContainerID: 0, HostID: gid, Size: 1},
+		},
+		// without switching to root inside the namespace the process is
+		// left as an unmapped user, with no privileges at all
+		Credential: &syscall.Credential{Uid: 0, Gid: 0, NoSetGroups: true},
+		// kill the job if the manager goes away
+		Pdeathsig: syscall.SIGKILL,
+	}
+
+	return cmd
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// input is the write end of the pipe connected to the stdin of a job,
+// or the terminal of a job started using WithTTY.
+type input struct {
+	// terminal is set if file is the terminal of the job, which isn't
+	// closed when the input ends.
+	terminal bool
+
+	mu sync.Mutex
+	// file is nil once stdin has been closed.
+	file *os.File
+	// busy is set while input is being sent, so only one sender writes
+	// to stdin at a time.
+	busy bool
+}
+
+// newInput creates the pipe for the stdin of a job, returning the read
+// end to pass to the job command. The caller must close the read end
+// once the job has started.
+func newInput() (*input, *os.File, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create stdin pipe: %w", err)
+	}
+	return &input{file: w}, r, nil
+}
+
+// claim returns stdin for sending input, if it's open and nobody else
+// is sending input. release must be called once the input has been sent.
+func (in *input) claim() (*os.File, error) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	switch {
+	case in.file == nil:
+		return nil, fmt.Errorf("%w: stdin has been closed", ErrNoInput)
+	case in.busy:
+		return nil, fmt.Errorf("%w: input is already being sent", ErrNoInput)
+	}
+	in.busy = true
+	return in.file, nil
+}
+
+// release lets someone else send input, closing stdin first if closeFile
+// is set and stdin isn't a terminal.
+func (in *input) release(closeFile bool) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.busy = false
+	if closeFile && !in.terminal {
+		in.closeLocked()
+	}
+}
+
+// close closes stdin, if it's still open. Anyone sending input when
+// stdin is closed gets an error.
+func (in *input) close() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.closeLocked()
+}
+
+func (in *input) closeLocked() {
+	if in.file != nil {
+		_ = in.file.Close()
+		in.file = nil
+	}
+}
+
+// SendInput copies r to the stdin of the job with the given ID, which
+// must have been started using WithStdin or WithTTY. Once r returns
+// io.EOF stdin is closed, so the job reads EOF as well, unless stdin is
+// a terminal. If reading r fails or ctx is
+// cancelled, stdin is left open so the rest of the input can be sent by
+// calling SendInput again. Only one call can send input to a job at a
+// time.
+//
+// The number of bytes written to stdin is always returned, even with an
+// error. ErrNoInput is returned if the job isn't accepting input.
+func (m *Manager) SendInput(ctx context.Context, id string, r io.Reader) (int64, error) {
+	j, err := m.getJob(id)
+	if err != nil {
+		return 0, err
+	}
+	if j.input == nil {
+		return 0, fmt.Errorf("%w: job '%v' wasn't started with stdin or a terminal", ErrNoInput, id)
+	}
+
+	f, err := j.input.claim()
+	if err != nil {
+		return 0, err
+	}
+
+	// writes block while the job isn't reading its stdin, so cancelling
+	// ctx has to interrupt them using a deadline
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			_ = f.SetWriteDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	n, err := io.Copy(f, r)
+	close(stop)
+	<-stopped
+
+	if err == nil {
+		j.input.release(true)
+		return n, nil
+	}
+
+	_ = f.SetWriteDeadline(time.Time{})
+	j.input.release(false)
+	switch {
+	case ctx.Err() != nil:
+		err = ctx.Err()
+	case errors.Is(err, os.ErrClosed), errors.Is(err, syscall.EPIPE):
+		err = fmt.Errorf("%w: job is no longer reading stdin", ErrNoInput)
+	}
+	return n, fmt.Errorf("unable to send input to job '%v': %w", id, err)
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAll returns all of the output of a job that has ended.
+func readAll(t *testing.T, m *Manager, id string) string {
+	t.Helper()
+	r, err := m.TailJob(context.Background(), id)
+	require.NoError(t, err)
+	defer r.Close()
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestManager_SendInput(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("cat", nil, WithStdin())
+	require.NoError(t, err)
+
+	n, err := m.SendInput(context.Background(), info.ID, strings.NewReader("hello\n"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), n)
+
+	// stdin is closed once all the input has been sent, so cat exits
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, StatusFinished, info.Status)
+	assert.Equal(t, "hello\n", readAll(t, m, info.ID))
+
+	_, err = m.SendInput(context.Background(), info.ID, strings.NewReader("too late"))
+	assert.True(t, errors.Is(err, ErrNoInput), "expected ErrNoInput, got %v", err)
+}
+
+func TestManager_SendInputWithoutStdin(t *testing.T) {
+	m := newTestManager(t)
+
+	// without stdin, cat reads EOF straight away
+	info, err := m.StartJob("cat", nil)
+	require.NoError(t, err)
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, StatusFinished, info.Status)
+
+	_, err = m.SendInput(context.Background(), info.ID, strings.NewReader("hello"))
+	assert.True(t, errors.Is(err, ErrNoInput), "expected ErrNoInput, got %v", err)
+
+	_, err = m.SendInput(context.Background(), "nope", strings.NewReader("hello"))
+	assert.True(t, errors.Is(err, ErrJobNotFound), "expected ErrJobNotFound, got %v", err)
+}
+
+// failingReader returns data, then fails.
+type failingReader struct {
+	data string
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestManager_SendInputResume(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("cat", nil, WithStdin())
+	require.NoError(t, err)
+
+	// a failed send leaves stdin open, so the rest can be sent later
+	errLost := errors.New("connection lost")
+	n, err := m.SendInput(context.Background(), info.ID, &failingReader{data: "one ", err: errLost})
+	assert.True(t, errors.Is(err, errLost), "expected errLost, got %v", err)
+	assert.Equal(t, int64(4), n)
+
+	_, err = m.SendInput(context.Background(), info.ID, strings.NewReader("two\n"))
+	require.NoError(t, err)
+
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, StatusFinished, info.Status)
+	assert.Equal(t, "one two\n", readAll(t, m, info.ID))
+}
+
+// endlessReader returns data forever.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func TestManager_SendInputCancel(t *testing.T) {
+	m := newTestManager(t)
+
+	// sleep never reads stdin, so the pipe fills up and writes block
+	info, err := m.StartJob("sleep", []string{"30"}, WithStdin())
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = m.StopJob(context.Background(), info.ID) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		_, err := m.SendInput(ctx, info.ID, endlessReader{})
+		errs <- err
+	}()
+
+	j, err := m.getJob(info.ID)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		j.input.mu.Lock()
+		defer j.input.mu.Unlock()
+		return j.input.busy
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// only one sender is allowed at a time
+	_, err = m.SendInput(context.Background(), info.ID, strings.NewReader("hello"))
+	assert.True(t, errors.Is(err, ErrNoInput), "expected ErrNoInput, got %v", err)
+
+	cancel()
+	select {
+	case err := <-errs:
+		assert.True(t, errors.Is(err, context.Canceled), "expected context.Canceled, got %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("sending input didn't stop after the context was cancelled")
+	}
+
+	// stopping the job while input is being sent ends the send
+	go func() {
+		_, err := m.SendInput(context.Background(), info.ID, endlessReader{})
+		errs <- err
+	}()
+	_, err = m.StopJob(context.Background(), info.ID)
+	require.NoError(t, err)
+	select {
+	case err := <-errs:
+		assert.True(t, errors.Is(err, ErrNoInput), "expected ErrNoInput, got %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("sending input didn't stop after the job ended")
+	}
+}
@@ -0,0 +1,388 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// timeoutGracePeriod is how long a job that has run for longer than its
+// max runtime gets to exit after being sent SIGTERM, before it's killed.
+const timeoutGracePeriod = 10 * time.Second
+
+// JobStatus describes what state a job is currently in.
+type JobStatus int
+
+const (
+	// StatusUnknown is the zero value. It's only seen in the
+	// EventCreated event for a job, before the job has started.
+	StatusUnknown JobStatus = iota
+	// StatusRunning means the job is still processing.
+	StatusRunning
+	// StatusFailed means the job either failed to start, or exited
+	// with a non-zero exit code.
+	StatusFailed
+	// StatusFinished means the job completed successfully.
+	StatusFinished
+	// StatusStopped means the job was stopped by a user before it
+	// finished.
+	StatusStopped
+	// StatusTimedOut means the job was stopped because it ran for longer
+	// than its max runtime.
+	StatusTimedOut
+)
+
+// String returns a human-readable version of the status.
+func (s JobStatus) String() string {
+	switch s {
+	case StatusRunning:
+		return "Running"
+	case StatusFailed:
+		return "Failed"
+	case StatusFinished:
+		return "Finished"
+	case StatusStopped:
+		return "Stopped"
+	case StatusTimedOut:
+		return "TimedOut"
+	}
+	return "Unknown"
+}
+
+// TerminationReason describes why a job ended.
+type TerminationReason int
+
+const (
+	// ReasonNone means the job hasn't ended yet.
+	ReasonNone TerminationReason = iota
+	// ReasonExited means the job command exited on its own, whether or
+	// not it was successful.
+	ReasonExited
+	// ReasonSignaled means the job was killed by a signal that didn't
+	// come from the manager.
+	ReasonSignaled
+	// ReasonUserStopped means the job was stopped using StopJob.
+	ReasonUserStopped
+	// ReasonOOMKilled means the job failed after a process in it was
+	// killed for using more memory than its limit allowed.
+	ReasonOOMKilled
+	// ReasonPidsExhausted means the job failed after it tried to start
+	// more processes than its limit allowed.
+	ReasonPidsExhausted
+	// ReasonTimeout means the job was stopped because it ran for longer
+	// than its max runtime.
+	ReasonTimeout
+)
+
+// String returns a human-readable version of the reason.
+func (r TerminationReason) String() string {
+	switch r {
+	case ReasonExited:
+		return "Exited"
+	case ReasonSignaled:
+		return "Signaled"
+	case ReasonUserStopped:
+		return "UserStopped"
+	case ReasonOOMKilled:
+		return "OOMKilled"
+	case ReasonPidsExhausted:
+		return "PidsExhausted"
+	case ReasonTimeout:
+		return "Timeout"
+	}
+	return "None"
+}
+
+// JobInfo is a snapshot of the state of a job at the time it was
+// requested.
+type JobInfo struct {
+	ID string
+	// Owner is the user that started the job, if one was given using
+	// WithOwner.
+	Owner    string
+	Status   JobStatus
+	Command  string
+	Args     []string
+	ErrorMsg string
+	// Limits are the resource limits applied to the job, which are only
+	// set if the manager was configured using WithCgroup.
+	Limits Limits
+	// MaxRuntime is how long the job is allowed to run, or zero if it can
+	// run forever.
+	MaxRuntime time.Duration
+	// TTY is set if the job was started using WithTTY.
+	TTY bool
+	// Signal is the signal that ended the job, or zero if the job
+	// exited on its own.
+	Signal syscall.Signal
+	// Reason is why the job ended. The OOM and pids reasons can only be
+	// detected when the manager was configured using WithCgroup.
+	Reason TerminationReason
+	// ExitCode is the exit code of the job command once it has ended,
+	// or -1 if it was ended by a signal.
+	ExitCode int
+	// Usage is the resources the job used, which is only set once the
+	// job has ended.
+	Usage     ResourceUsage
+	StartedAt time.Time
+	EndedAt   time.Time
+}
+
+// job is the manager's internal record of a job it has started.
+type job struct {
+	id      xid.ID
+	owner   string
+	command string
+	args    []string
+	// maxRuntime is how long the job can run before it's stopped, or
+	// zero if it can run forever.
+	maxRuntime time.Duration
+
+	cmd *exec.Cmd
+	// pidNS is the PID namespace of the job, which every process
+	// started by the job is in.
+	pidNS  string
+	output *output
+	// input is the stdin of the job, or nil if it wasn't started using
+	// WithStdin or WithTTY.
+	input *input
+	// terminal is the terminal of the job, or nil if it wasn't started
+	// using WithTTY.
+	terminal *terminal
+	dir      string
+	rootfs   string
+	cgroup   *cgroup
+	limits   Limits
+	// events is where the event is sent once the job has ended, which is
+	// nil if the job was never added to the manager.
+	events *eventBus
+
+	// done is closed once the process has exited and the job state
+	// has been updated.
+	done chan struct{}
+
+	mu        sync.Mutex
+	status    JobStatus
+	errorMsg  string
+	stopped   bool
+	timedOut  bool
+	signal    syscall.Signal
+	reason    TerminationReason
+	exitCode  int
+	usage     ResourceUsage
+	startedAt time.Time
+	endedAt   time.Time
+}
+
+// info returns a snapshot of the job.
+func (j *job) info() *JobInfo {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	args := make([]string, len(j.args))
+	copy(args, j.args)
+
+	return &JobInfo{
+		ID:         j.id.String(),
+		Owner:      j.owner,
+		Status:     j.status,
+		Command:    j.command,
+		Args:       args,
+		ErrorMsg:   j.errorMsg,
+		Limits:     j.limits,
+		MaxRuntime: j.maxRuntime,
+		TTY:        j.terminal != nil,
+		Signal:     j.signal,
+		Reason:     j.reason,
+		ExitCode:   j.exitCode,
+		Usage:      j.usage,
+		StartedAt:  j.startedAt,
+		EndedAt:    j.endedAt,
+	}
+}
+
+// stop stops the job if it's still running, and waits until the job
+// has ended or ctx is cancelled. If gracePeriod is more than zero the
+// job is sent SIGTERM first, and is only killed if it's still running
+// once gracePeriod has passed; that happens even if ctx is cancelled
+// before then. timedOut is set when the job is being stopped because it
+// ran for too long.
+func (j *job) stop(ctx context.Context, gracePeriod time.Duration, timedOut bool) error {
+	if j.markStopped(timedOut) {
+		if gracePeriod > 0 {
+			if err := j.kill(syscall.SIGTERM); err != nil {
+				return err
+			}
+			go j.killAfter(gracePeriod)
+		} else if err := j.kill(syscall.SIGKILL); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case <-j.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// markStopped records that the job is being stopped, returning false if
+// it had already ended. wait holds the same lock while it works out how
+// the job ended, so a job is only reported as stopped if this was called
+// while it was still running.
+func (j *job) markStopped(timedOut bool) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.status != StatusRunning {
+		return false
+	}
+	j.stopped = true
+	if timedOut {
+		j.timedOut = true
+	}
+	return true
+}
+
+// killAfter kills the job once gracePeriod has passed, unless it ends
+// before then.
+func (j *job) killAfter(gracePeriod time.Duration) {
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-j.done:
+	case <-timer.C:
+		_ = j.kill(syscall.SIGKILL)
+	}
+}
+
+// timeout stops the job once it has run for longer than its max
+// runtime, unless it ends before then.
+func (j *job) timeout() {
+	timer := time.NewTimer(j.maxRuntime)
+	defer timer.Stop()
+
+	select {
+	case <-j.done:
+		return
+	case <-timer.C:
+	}
+
+	_ = j.stop(context.Background(), timeoutGracePeriod, true)
+}
+
+// kill sends sig to every process in the job. The job command is the
+// init process of the job's PID namespace, so once it exits the kernel
+// kills everything else in the namespace; SIGKILL only needs to be sent
+// to it, and to the cgroup as a belt-and-braces measure.
+func (j *job) kill(sig syscall.Signal) error {
+	if sig == syscall.SIGKILL {
+		if j.cgroup != nil {
+			_ = j.cgroup.kill()
+		}
+	} else if j.pidNS != "" {
+		// any other signal is sent to the rest of the job first, so
+		// that children are told to stop as well as the init process
+		pids, err := namespaceProcesses(j.pidNS)
+		if err != nil {
+			return err
+		}
+		for _, pid := range pids {
+			if pid != j.cmd.Process.Pid {
+				_ = syscall.Kill(pid, sig)
+			}
+		}
+	}
+
+	err := j.cmd.Process.Signal(sig)
+	if err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	return nil
+}
+
+// wait blocks until the process exits, cleans up after the job, then
+// records the outcome.
+func (j *job) wait() {
+	// Wait doesn't return until everything the job wrote to stdout &
+	// stderr has been copied to the output
+	err := j.cmd.Wait()
+	now := time.Now()
+
+	// the usage and events have to be read before the cgroup is removed
+	usage := processUsage(j.cmd.ProcessState)
+	var events cgroupEvents
+	if j.cgroup != nil {
+		j.cgroup.usage(&usage)
+		events = j.cgroup.events()
+	}
+
+	if j.rootfs != "" {
+		// the mounts inside the root filesystem only existed in the
+		// mount namespace of the job, which is gone now
+		_ = os.RemoveAll(j.rootfs)
+	}
+	if j.cgroup != nil {
+		_ = j.cgroup.remove()
+	}
+
+	j.mu.Lock()
+	j.endedAt = now
+	j.exitCode = j.cmd.ProcessState.ExitCode()
+	j.usage = usage
+	j.reason = ReasonExited
+	if ws, ok := j.cmd.ProcessState.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		j.signal = ws.Signal()
+		j.reason = ReasonSignaled
+	}
+	switch {
+	case j.timedOut && j.stopped:
+		j.status = StatusTimedOut
+		j.reason = ReasonTimeout
+	case j.stopped:
+		j.status = StatusStopped
+		j.reason = ReasonUserStopped
+	case err != nil:
+		j.status = StatusFailed
+		j.errorMsg = err.Error()
+		// hitting a limit only explains the failure if the job didn't
+		// cope with it
+		switch {
+		case events.oomKills > 0:
+			j.reason = ReasonOOMKilled
+			j.errorMsg = "out of memory: " + j.errorMsg
+		case events.pidsMax > 0:
+			j.reason = ReasonPidsExhausted
+			j.errorMsg = "process limit reached: " + j.errorMsg
+		}
+	default:
+		j.status = StatusFinished
+	}
+	j.mu.Unlock()
+
+	if j.input != nil {
+		j.input.close()
+	}
+	if j.terminal != nil {
+		// the output can't be closed until everything has been copied
+		// from the terminal
+		j.terminal.close()
+	}
+
+	// closing the output only after the status has been updated means
+	// anyone tailing the job sees the final status once they reach the
+	// end of the output
+	_ = j.output.Close()
+	if j.events != nil {
+		j.events.publish(endEvents[j.status], j.info())
+	}
+	close(j.done)
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// defaultCPUPeriod is the period the kernel uses for 'cpu.max' if one
+// isn't provided.
+const defaultCPUPeriod = 100 * time.Millisecond
+
+// ioDevicePattern matches the "major:minor" number of a block device.
+var ioDevicePattern = regexp.MustCompile(`^[0-9]+:[0-9]+$`)
+
+// Limits are the resource limits applied to a job through its cgroup.
+// A zero value for any field means that resource isn't limited.
+type Limits struct {
+	// MaxPids is the maximum number of processes the job can have
+	// running at once, written to 'pids.max'.
+	MaxPids int64
+	// CPUQuota is how much CPU time the job can use every CPUPeriod,
+	// written to 'cpu.max'.
+	CPUQuota time.Duration
+	// CPUPeriod is the period CPUQuota applies to. If it's zero the
+	// kernel default is used.
+	CPUPeriod time.Duration
+	// MemoryBytes is the maximum amount of memory the job can use,
+	// written to 'memory.max'.
+	MemoryBytes int64
+	// IO limits the bytes per second the job can read from or write
+	// to block devices, written to 'io.max'.
+	IO []IOLimit
+}
+
+// IOLimit limits the read & write bandwidth for a single block device.
+type IOLimit struct {
+	// Device is the "major:minor" number of the block device.
+	Device string
+	// ReadBPS is the maximum number of bytes per second that can be
+	// read from the device.
+	ReadBPS int64
+	// WriteBPS is the maximum number of bytes per second that can be
+	// written to the device.
+	WriteBPS int64
+}
+
+// DefaultLimits are the limits applied to jobs if the manager hasn't
+// been configured with different ones. IO isn't limited by default, as
+// that requires knowing which block devices the host has.
+var DefaultLimits = Limits{
+	MaxPids:     10,
+	CPUQuota:    200 * time.Millisecond,
+	CPUPeriod:   time.Second,
+	MemoryBytes: 10 * 1024 * 1024,
+}
+
+// merge returns a copy of l with any zero fields replaced by the
+// values in defaults.
+func (l Limits) merge(defaults Limits) Limits {
+	if l.MaxPids == 0 {
+		l.MaxPids = defaults.MaxPids
+	}
+	if l.CPUQuota == 0 {
+		l.CPUQuota = defaults.CPUQuota
+	}
+	if l.CPUPeriod == 0 {
+		l.CPUPeriod = defaults.CPUPeriod
+	}
+	if l.MemoryBytes == 0 {
+		l.MemoryBytes = defaults.MemoryBytes
+	}
+
+	// limits for devices the job didn't set are taken from defaults
+	io := make([]IOLimit, 0, len(l.IO)+len(defaults.IO))
+	io = append(io, l.IO...)
+	for _, def := range defaults.IO {
+		found := false
+		for _, limit := range l.IO {
+			if limit.Device == def.Device {
+				found = true
+			}
+		}
+		if !found {
+			io = append(io, def)
+		}
+	}
+	l.IO = nil
+	if len(io) > 0 {
+		l.IO = io
+	}
+	return l
+}
+
+// WithDefaultLimits sets the limits used for any job that doesn't
+// provide its own. If this option isn't provided, DefaultLimits is
+// used.
+func WithDefaultLimits(limits Limits) Option {
+	return func(m *Manager) error {
+		if err := limits.validate(); err != nil {
+			return fmt.Errorf("invalid default limits: %w", err)
+		}
+		m.defaultLimits = limits
+		return nil
+	}
+}
+
+// WithLimitCeilings sets the maximum limits a job is allowed to ask for.
+// StartJob returns ErrInvalidLimits if the limits for a job are higher
+// than these. A zero value for any field means there is no ceiling for
+// that resource.
+func WithLimitCeilings(ceilings Limits) Option {
+	return func(m *Manager) error {
+		if err := ceilings.validate(); err != nil {
+			return fmt.Errorf("invalid limit ceilings: %w", err)
+		}
+		m.limitCeilings = ceilings
+		return nil
+	}
+}
+
+// validate checks that the limits make sense on their own.
+func (l Limits) validate() error {
+	if l.MaxPids < 0 {
+		return fmt.Errorf("%w: max pids can't be negative", ErrInvalidLimits)
+	}
+	if l.CPUQuota < 0 || l.CPUPeriod < 0 {
+		return fmt.Errorf("%w: cpu quota and period can't be negative", ErrInvalidLimits)
+	}
+	if l.CPUQuota > 0 && l.CPUQuota < time.Microsecond {
+		return fmt.Errorf("%w: cpu quota must be at least 1 microsecond", ErrInvalidLimits)
+	}
+	if l.CPUPeriod > 0 && l.CPUPeriod < time.Microsecond {
+		return fmt.Errorf("%w: cpu period must be at least 1 microsecond", ErrInvalidLimits)
+	}
+	if l.MemoryBytes < 0 {
+		return fmt.Errorf("%w: memory can't be negative", ErrInvalidLimits)
+	}
+
+	seen := map[string]bool{}
+	for _, io := range l.IO {
+		if !ioDevicePattern.MatchString(io.Device) {
+			return fmt.Errorf("%w: io device '%v' isn't in the form 'major:minor'", ErrInvalidLimits, io.Device)
+		}
+		if seen[io.Device] {
+			return fmt.Errorf("%w: io device '%v' has more than one limit", ErrInvalidLimits, io.Device)
+		}
+		seen[io.Device] = true
+
+		if io.ReadBPS < 0 || io.WriteBPS < 0 {
+			return fmt.Errorf("%w: io limits for device '%v' can't be negative", ErrInvalidLimits, io.Device)
+		}
+	}
+
+	return nil
+}
+
+// checkCeilings checks that the limits don't go over any of the
+// ceilings. A limit of zero means "unlimited", so it's over any
+// ceiling that has been set.
+func (l Limits) checkCeilings(ceilings Limits) error {
+	if ceilings.MaxPids > 0 && (l.MaxPids == 0 || l.MaxPids > ceilings.MaxPids) {
+		return fmt.Errorf("%w: max pids can't be more than %v", ErrInvalidLimits, ceilings.MaxPids)
+	}
+
+	if ceilings.CPUQuota > 0 {
+		// compare the fraction of a CPU each allows, quota/period, by
+		// cross-multiplying to avoid floating point
+		period, ceilingPeriod := l.CPUPeriod, ceilings.CPUPeriod
+		if period == 0 {
+			period = defaultCPUPeriod
+		}
+		if ceilingPeriod == 0 {
+			ceilingPeriod = defaultCPUPeriod
+		}
+		if l.CPUQuota == 0 || l.CPUQuota.Microseconds()*ceilingPeriod.Microseconds() > ceilings.CPUQuota.Microseconds()*period.Microseconds() {
+			return fmt.Errorf("%w: cpu can't be more than %v every %v", ErrInvalidLimits, ceilings.CPUQuota, ceilingPeriod)
+		}
+	}
+
+	if ceilings.MemoryBytes > 0 && (l.MemoryBytes == 0 || l.MemoryBytes > ceilings.MemoryBytes) {
+		return fmt.Errorf("%w: memory can't be more than %v bytes", ErrInvalidLimits, ceilings.MemoryBytes)
+	}
+
+	for _, ceiling := range ceilings.IO {
+		limit := IOLimit{Device: ceiling.Device}
+		for _, io := range l.IO {
+			if io.Device == ceiling.Device {
+				limit = io
+			}
+		}
+
+		if ceiling.ReadBPS > 0 && (limit.ReadBPS == 0 || limit.ReadBPS > ceiling.ReadBPS) {
+			return fmt.Errorf("%w: reads from device '%v' can't be more than %v bytes per second",
+				ErrInvalidLimits, ceiling.Device, ceiling.ReadBPS)
+		}
+		if ceiling.WriteBPS > 0 && (limit.WriteBPS == 0 || limit.WriteBPS > ceiling.WriteBPS) {
+			return fmt.Errorf("%w: writes to device '%v' can't be more than %v bytes per second",
+				ErrInvalidLimits, ceiling.Device, ceiling.WriteBPS)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimits_Merge(t *testing.T) {
+	defaults := Limits{
+		MaxPids:     10,
+		CPUQuota:    200 * time.Millisecond,
+		CPUPeriod:   time.Second,
+		MemoryBytes: 1024,
+		IO: []IOLimit{
+			{Device: "8:0", ReadBPS: 100},
+			{Device: "8:16", WriteBPS: 100},
+		},
+	}
+
+	assert.Equal(t, defaults, Limits{}.merge(defaults))
+
+	got := Limits{
+		MaxPids:     20,
+		MemoryBytes: 4096,
+		IO:          []IOLimit{{Device: "8:16", WriteBPS: 50}},
+	}.merge(defaults)
+	assert.Equal(t, Limits{
+		MaxPids:     20,
+		CPUQuota:    200 * time.Millisecond,
+		CPUPeriod:   time.Second,
+		MemoryBytes: 4096,
+		IO: []IOLimit{
+			{Device: "8:16", WriteBPS: 50},
+			{Device: "8:0", ReadBPS: 100},
+		},
+	}, got)
+}
+
+func TestLimits_Validate(t *testing.T) {
+	tests := map[string]Limits{
+		"negative pids":      {MaxPids: -1},
+		"negative cpu":       {CPUQuota: -time.Second},
+		"tiny cpu period":    {CPUQuota: time.Second, CPUPeriod: time.Nanosecond},
+		"negative memory":    {MemoryBytes: -1},
+		"bad device":         {IO: []IOLimit{{Device: "sda", ReadBPS: 1}}},
+		"negative io":        {IO: []IOLimit{{Device: "8:0", ReadBPS: -1}}},
+		"duplicate device":   {IO: []IOLimit{{Device: "8:0", ReadBPS: 1}, {Device: "8:0", WriteBPS: 1}}},
+		"device with spaces": {IO: []IOLimit{{Device: "8:0 rbps=1", ReadBPS: 1}}},
+	}
+
+	for name, limits := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := limits.validate()
+			assert.True(t, errors.Is(err, ErrInvalidLimits), "expected ErrInvalidLimits, got %v", err)
+		})
+	}
+
+	assert.NoError(t, Limits{}.validate())
+	assert.NoError(t, DefaultLimits.validate())
+}
+
+func TestLimits_CheckCeilings(t *testing.T) {
+	ceilings := Limits{
+		MaxPids:     20,
+		CPUQuota:    500 * time.Millisecond,
+		CPUPeriod:   time.Second,
+		MemoryBytes: 100 * 1024 * 1024,
+		IO:          []IOLimit{{Device: "8:0", ReadBPS: 1024}},
+	}
+
+	ok := map[string]Limits{
+		"at ceilings": ceilings,
+		"under ceilings": {
+			MaxPids:     5,
+			CPUQuota:    100 * time.Millisecond,
+			CPUPeriod:   time.Second,
+			MemoryBytes: 1024,
+			IO:          []IOLimit{{Device: "8:0", ReadBPS: 512}},
+		},
+		"same cpu fraction, different period": {
+			MaxPids:     5,
+			CPUQuota:    50 * time.Millisecond,
+			CPUPeriod:   100 * time.Millisecond,
+			MemoryBytes: 1024,
+			IO:          []IOLimit{{Device: "8:0", ReadBPS: 512}},
+		},
+	}
+	for name, limits := range ok {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, limits.checkCeilings(ceilings))
+		})
+	}
+
+	over := map[string]Limits{
+		"too many pids":    {MaxPids: 21, CPUQuota: time.Millisecond, MemoryBytes: 1, IO: ceilings.IO},
+		"unlimited pids":   {CPUQuota: time.Millisecond, MemoryBytes: 1, IO: ceilings.IO},
+		"too much cpu":     {MaxPids: 1, CPUQuota: 60 * time.Millisecond, CPUPeriod: 100 * time.Millisecond, MemoryBytes: 1, IO: ceilings.IO},
+		"too much memory":  {MaxPids: 1, CPUQuota: time.Millisecond, MemoryBytes: 200 * 1024 * 1024, IO: ceilings.IO},
+		"too much io":      {MaxPids: 1, CPUQuota: time.Millisecond, MemoryBytes: 1, IO: []IOLimit{{Device: "8:0", ReadBPS: 2048}}},
+		"unlimited device": {MaxPids: 1, CPUQuota: time.Millisecond, MemoryBytes: 1},
+	}
+	for name, limits := range over {
+		t.Run(name, func(t *testing.T) {
+			err := limits.checkCeilings(ceilings)
+			assert.True(t, errors.Is(err, ErrInvalidLimits), "expected ErrInvalidLimits, got %v", err)
+		})
+	}
+
+	// no ceilings means anything goes
+	assert.NoError(t, Limits{}.checkCeilings(Limits{}))
+}
+
+func TestManager_LimitCeilings(t *testing.T) {
+	ceilings := Limits{MaxPids: 20, MemoryBytes: 64 * 1024 * 1024}
+
+	// the defaults have to fit under the ceilings
+	_, err := NewManager(WithWorkDir(t.TempDir()), WithLimitCeilings(Limits{MaxPids: 5}))
+	assert.Error(t, err)
+
+	m, err := NewManager(WithWorkDir(t.TempDir()), WithLimitCeilings(ceilings))
+	require.NoError(t, err)
+
+	_, err = m.StartJob("true", nil, WithLimits(Limits{MaxPids: 50}))
+	assert.True(t, errors.Is(err, ErrInvalidLimits), "expected ErrInvalidLimits, got %v", err)
+
+	_, err = m.StartJob("true", nil, WithLimits(Limits{MemoryBytes: -1}))
+	assert.True(t, errors.Is(err, ErrInvalidLimits), "expected ErrInvalidLimits, got %v", err)
+
+	_, err = m.StartJob("true", nil, WithLimits(Limits{MemoryBytes: 32 * 1024 * 1024}))
+	require.NoError(t, err)
+}
+
+func TestManager_LimitsWithoutCgroup(t *testing.T) {
+	m, err := NewManager(WithWorkDir(t.TempDir()), WithLimitCeilings(Limits{MaxPids: 20}))
+	require.NoError(t, err)
+
+	// limits are still checked against the ceilings, so a job that works
+	// here works the same once a cgroup is configured
+	_, err = m.StartJob("true", nil, WithLimits(Limits{MaxPids: 50}))
+	assert.True(t, errors.Is(err, ErrInvalidLimits), "expected ErrInvalidLimits, got %v", err)
+
+	// but as nothing enforces them, they aren't reported as the limits of
+	// the job; see TestManager_Cgroup for when they are
+	info, err := m.StartJob("true", nil, WithLimits(Limits{MemoryBytes: 32 * 1024 * 1024}))
+	require.NoError(t, err)
+	assert.Equal(t, Limits{}, info.Limits)
+}
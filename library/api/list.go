@@ -0,0 +1,90 @@
+package api
+
+import (
+	"sort"
+	"time"
+)
+
+// JobFilter picks which jobs are returned by ListJobs. A job has to
+// match every field that's set; fields left as their zero value match
+// every job.
+type JobFilter struct {
+	// Statuses matches jobs in any of the given states.
+	Statuses []JobStatus
+	// Owner matches jobs started by the given user.
+	Owner string
+	// Command matches jobs running exactly the given command.
+	Command string
+	// StartedAfter and StartedBefore match jobs started at or after, and
+	// before, the given times.
+	StartedAfter  time.Time
+	StartedBefore time.Time
+	// AfterID matches jobs started after the job with the given ID, so
+	// that the jobs can be fetched a page at a time. The job doesn't
+	// have to exist any more.
+	AfterID string
+	// Limit is the maximum number of jobs to return, zero means there is
+	// no limit.
+	Limit int
+}
+
+// ListJobs returns the jobs that match filter, in the order they were
+// started.
+func (m *Manager) ListJobs(filter JobFilter) []*JobInfo {
+	m.mu.RLock()
+	jobs := make([]*job, 0, len(m.jobs))
+	for id, j := range m.jobs {
+		// IDs sort in the order they were created
+		if filter.AfterID == "" || id > filter.AfterID {
+			jobs = append(jobs, j)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(jobs, func(a, b int) bool {
+		return jobs[a].id.Compare(jobs[b].id) < 0
+	})
+
+	out := []*JobInfo{}
+	for _, j := range jobs {
+		info := j.info()
+		if !filter.matches(info) {
+			continue
+		}
+		out = append(out, info)
+		if filter.Limit > 0 && len(out) == filter.Limit {
+			break
+		}
+	}
+	return out
+}
+
+// matches reports if the job matches the filter, ignoring the paging
+// fields.
+func (f JobFilter) matches(info *JobInfo) bool {
+	if len(f.Statuses) > 0 && !hasStatus(f.Statuses, info.Status) {
+		return false
+	}
+	if f.Owner != "" && info.Owner != f.Owner {
+		return false
+	}
+	if f.Command != "" && info.Command != f.Command {
+		return false
+	}
+	if !f.StartedAfter.IsZero() && info.StartedAt.Before(f.StartedAfter) {
+		return false
+	}
+	if !f.StartedBefore.IsZero() && !info.StartedAt.Before(f.StartedBefore) {
+		return false
+	}
+	return true
+}
+
+func hasStatus(statuses []JobStatus, status JobStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_ListJobs(t *testing.T) {
+	m := newTestManager(t)
+
+	start := func(command string, args []string, owner string) *JobInfo {
+		t.Helper()
+		info, err := m.StartJob(command, args, WithOwner(owner))
+		require.NoError(t, err)
+		return info
+	}
+	first := start("true", nil, "alice")
+	waitForJob(t, m, first.ID)
+	second := start("sleep", []string{"30"}, "bob")
+	third := start("sleep", []string{"30"}, "alice")
+	t.Cleanup(func() { _ = m.Shutdown() })
+
+	ids := func(jobs []*JobInfo) []string {
+		out := []string{}
+		for _, j := range jobs {
+			out = append(out, j.ID)
+		}
+		return out
+	}
+
+	tests := map[string]struct {
+		filter JobFilter
+		expect []string
+	}{
+		"everything":            {JobFilter{}, []string{first.ID, second.ID, third.ID}},
+		"status":                {JobFilter{Statuses: []JobStatus{StatusRunning}}, []string{second.ID, third.ID}},
+		"statuses":              {JobFilter{Statuses: []JobStatus{StatusFinished, StatusFailed}}, []string{first.ID}},
+		"owner":                 {JobFilter{Owner: "alice"}, []string{first.ID, third.ID}},
+		"command":               {JobFilter{Command: "sleep"}, []string{second.ID, third.ID}},
+		"owner command":         {JobFilter{Owner: "alice", Command: "sleep"}, []string{third.ID}},
+		"no match":              {JobFilter{Owner: "charlie"}, []string{}},
+		"started after":         {JobFilter{StartedAfter: second.StartedAt}, []string{second.ID, third.ID}},
+		"started before":        {JobFilter{StartedBefore: second.StartedAt}, []string{first.ID}},
+		"started in the future": {JobFilter{StartedAfter: time.Now().Add(time.Hour)}, []string{}},
+		"limit":                 {JobFilter{Limit: 2}, []string{first.ID, second.ID}},
+		"after id":              {JobFilter{AfterID: first.ID}, []string{second.ID, third.ID}},
+		"after id and limit":    {JobFilter{AfterID: first.ID, Limit: 1}, []string{second.ID}},
+		"after last":            {JobFilter{AfterID: third.ID}, []string{}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, ids(m.ListJobs(tt.filter)))
+		})
+	}
+}
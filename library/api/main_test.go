@@ -0,0 +1,12 @@
+package api
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	// jobs are started by re-executing the test binary
+	Init()
+	os.Exit(m.Run())
+}
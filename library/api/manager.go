@@ -0,0 +1,646 @@
+// Package api contains the job manager; the part of workernator that
+// launches jobs, keeps track of them, stops them, and captures their
+// output so it can be streamed back to any number of readers.
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+const outputFileName = "output"
+
+// Option configures a Manager.
+type Option func(*Manager) error
+
+// WithWorkDir sets the directory the manager uses to store per-job
+// data such as captured output. If this option isn't provided, a new
+// temporary directory is created. If dir doesn't exist it's created so
+// that jobs can search it but not list it; when WithRootFS is used, an
+// existing dir and all its parents have to be searchable by the user
+// jobs run as.
+func WithWorkDir(dir string) Option {
+	return func(m *Manager) error {
+		// the root filesystems unpacked inside have to be reachable by
+		// the jobs, which don't run as the same user as the manager
+		if err := os.MkdirAll(dir, 0o711); err != nil { //nolint:gosec // nothing in it can be listed
+			return fmt.Errorf("unable to create work directory '%v': %w", dir, err)
+		}
+		m.workDir = dir
+		return nil
+	}
+}
+
+// WithMaxRuntimeCeiling sets the longest any job is allowed to run.
+// Jobs started without WithMaxRuntime use it as their max runtime, and
+// StartJob returns ErrInvalidLimits for jobs that ask for longer. Zero,
+// the default, means jobs can run forever.
+func WithMaxRuntimeCeiling(ceiling time.Duration) Option {
+	return func(m *Manager) error {
+		if ceiling < 0 {
+			return fmt.Errorf("%w: max runtime ceiling can't be negative", ErrInvalidLimits)
+		}
+		m.maxRuntime = ceiling
+		return nil
+	}
+}
+
+// Manager starts, stops, and keeps track of jobs.
+type Manager struct {
+	workDir       string
+	rootFS        string
+	cgroupParent  string
+	defaultLimits Limits
+	limitCeilings Limits
+	maxRuntime    time.Duration
+	events        *eventBus
+	// jobUID and jobGID are the user and group IDs on the host that
+	// root inside each job maps to.
+	jobUID int
+	jobGID int
+
+	mu   sync.RWMutex
+	jobs map[string]*job
+	// closed is set by Shutdown, after which no more jobs can be started.
+	closed bool
+}
+
+// NewManager builds a Manager, applying any options provided.
+func NewManager(opts ...Option) (*Manager, error) {
+	m := &Manager{
+		defaultLimits: DefaultLimits,
+		jobs:          map[string]*job{},
+		events:        newEventBus(),
+		jobUID:        defaultJobID(os.Getuid()),
+		jobGID:        defaultJobID(os.Getgid()),
+	}
+
+	for _, opt := range opts {
+		if err := opt(m); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.defaultLimits.checkCeilings(m.limitCeilings); err != nil {
+		return nil, fmt.Errorf("default limits are higher than the limit ceilings: %w", err)
+	}
+
+	if m.workDir == "" {
+		dir, err := os.MkdirTemp("", "workernator-")
+		if err != nil {
+			return nil, fmt.Errorf("unable to create work directory: %w", err)
+		}
+		// the root filesystems unpacked inside have to be reachable by
+		// the jobs, which don't run as the same user as the manager
+		if err := os.Chmod(dir, 0o711); err != nil { //nolint:gosec // nothing in it can be listed
+			return nil, fmt.Errorf("unable to set mode of work directory: %w", err)
+		}
+		m.workDir = dir
+	}
+
+	if m.rootFS != "" {
+		if err := checkSearchable(m.workDir, m.jobUID, m.jobGID); err != nil {
+			return nil, fmt.Errorf("unable to use work directory '%v' with a root filesystem: %w", m.workDir, err)
+		}
+	}
+
+	return m, nil
+}
+
+// JobOption configures a single job started by StartJob.
+type JobOption func(*jobConfig)
+
+// jobConfig holds the settings for a job that can be changed using a
+// JobOption.
+type jobConfig struct {
+	limits     Limits
+	owner      string
+	maxRuntime time.Duration
+	stdin      bool
+	tty        bool
+}
+
+// WithLimits sets the resource limits for the job. Any fields left as
+// zero use the value from the default limits of the manager, and the
+// result must not be higher than the limit ceilings of the manager.
+// Limits are only applied, and only reported in JobInfo, if the manager
+// was configured using WithCgroup; they're checked against the ceilings
+// either way.
+func WithLimits(limits Limits) JobOption {
+	return func(c *jobConfig) {
+		c.limits = limits
+	}
+}
+
+// WithOwner records the user that started the job.
+func WithOwner(owner string) JobOption {
+	return func(c *jobConfig) {
+		c.owner = owner
+	}
+}
+
+// WithMaxRuntime sets how long the job is allowed to run. Once it has
+// run for longer it's sent SIGTERM, killed if it's still running after
+// a grace period, and ends with StatusTimedOut. Jobs that don't set a
+// max runtime use the ceiling set using WithMaxRuntimeCeiling.
+func WithMaxRuntime(maxRuntime time.Duration) JobOption {
+	return func(c *jobConfig) {
+		c.maxRuntime = maxRuntime
+	}
+}
+
+// WithStdin keeps the stdin of the job open so that input can be sent
+// to it using SendInput. Without it the job reads EOF from stdin
+// straight away.
+func WithStdin() JobOption {
+	return func(c *jobConfig) {
+		c.stdin = true
+	}
+}
+
+// WithTTY runs the job with a pseudo-terminal as its stdin, stdout, and
+// stderr, so that it can be used interactively. Everything the job
+// writes to the terminal is recorded as stdout. Input can be sent using
+// SendInput, but the terminal stays open once the input ends, so send
+// an end-of-file character ( Ctrl-D ) to tell the job there's no more
+// input. The window size can be changed using ResizeTerminal.
+func WithTTY() JobOption {
+	return func(c *jobConfig) {
+		c.tty = true
+	}
+}
+
+// StartJob launches a new job running command with the provided
+// arguments. It returns as soon as the process has been started. An
+// error is returned only if the job couldn't be started.
+func (m *Manager) StartJob(command string, args []string, opts ...JobOption) (*JobInfo, error) {
+	if command == "" {
+		return nil, fmt.Errorf("%w: no command provided", ErrInvalidCommand)
+	}
+	if m.isClosed() {
+		return nil, ErrManagerClosed
+	}
+
+	var conf jobConfig
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	if err := conf.limits.validate(); err != nil {
+		return nil, err
+	}
+	limits := conf.limits.merge(m.defaultLimits)
+	if err := limits.checkCeilings(m.limitCeilings); err != nil {
+		return nil, err
+	}
+	maxRuntime, err := m.jobMaxRuntime(conf.maxRuntime)
+	if err != nil {
+		return nil, err
+	}
+
+	id := xid.New()
+	dir := filepath.Join(m.workDir, id.String())
+	// the job directory can be searched but not listed, so that the job
+	// can reach its root filesystem; everything else in it is private
+	if err := os.MkdirAll(dir, 0o711); err != nil { //nolint:gosec // see above
+		return nil, fmt.Errorf("unable to create job directory: %w", err)
+	}
+
+	output, err := newOutput(filepath.Join(dir, outputFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	var rootfs string
+	if m.rootFS != "" {
+		rootfs = filepath.Join(dir, rootFSDirName)
+		if err := unpackRootFS(m.rootFS, rootfs, m.jobUID, m.jobGID); err != nil {
+			_ = output.Close()
+			_ = os.RemoveAll(dir)
+			return nil, fmt.Errorf("unable to unpack root filesystem: %w", err)
+		}
+	}
+
+	var cg *cgroup
+	if m.cgroupParent != "" {
+		cg, err = newCgroup(m.cgroupParent, id.String(), limits)
+		if err != nil {
+			_ = output.Close()
+			_ = os.RemoveAll(dir)
+			return nil, fmt.Errorf("unable to create job cgroup: %w", err)
+		}
+	}
+
+	cmd := initCommand(id.String(), rootfs, command, args, m.jobUID, m.jobGID)
+	cmd.Stdout = output.writer(StreamStdout)
+	cmd.Stderr = output.writer(StreamStderr)
+
+	var in *input
+	var term *terminal
+	switch {
+	case conf.tty:
+		var slave *os.File
+		term, slave, err = openTerminal()
+		if err == nil {
+			in, err = term.input()
+			if err != nil {
+				_ = slave.Close()
+				_ = term.master.Close()
+			}
+		}
+		if err != nil {
+			_ = output.Close()
+			_ = os.RemoveAll(dir)
+			if cg != nil {
+				_ = cg.remove()
+			}
+			return nil, err
+		}
+		// the job has its own copy once it has started, and the terminal
+		// only reports the job has finished with it once every copy has
+		// been closed
+		defer func() { _ = slave.Close() }()
+		term.setup(cmd, slave)
+	case conf.stdin:
+		var stdin *os.File
+		in, stdin, err = newInput()
+		if err != nil {
+			_ = output.Close()
+			_ = os.RemoveAll(dir)
+			if cg != nil {
+				_ = cg.remove()
+			}
+			return nil, err
+		}
+		// the job has its own copy once it has started, or doesn't need
+		// it if it couldn't be started
+		defer func() { _ = stdin.Close() }()
+		cmd.Stdin = stdin
+	}
+
+	j := &job{
+		id:         id,
+		owner:      conf.owner,
+		command:    command,
+		args:       args,
+		maxRuntime: maxRuntime,
+		cmd:        cmd,
+		output:     output,
+		input:      in,
+		terminal:   term,
+		dir:        dir,
+		rootfs:     rootfs,
+		cgroup:     cg,
+		done:       make(chan struct{}),
+	}
+	if cg != nil {
+		// limits are only recorded if they're actually being applied;
+		// reporting limits nothing enforces would tell clients the job is
+		// more constrained than it is
+		j.limits = limits
+	}
+	created := j.info()
+
+	err = startInit(cmd, func(pid int) error {
+		if cg == nil {
+			return nil
+		}
+		return cg.addProcess(pid)
+	})
+	if err != nil {
+		_ = output.Close()
+		if in != nil {
+			in.close()
+		}
+		if term != nil {
+			_ = term.master.Close()
+		}
+		_ = os.RemoveAll(dir)
+		if cg != nil {
+			_ = cg.remove()
+		}
+		return nil, err
+	}
+	j.status = StatusRunning
+	j.startedAt = time.Now()
+	// without the namespace, stopping the job only signals the init
+	// process; the kernel still kills everything else once it exits
+	j.pidNS, _ = pidNamespace(cmd.Process.Pid)
+
+	// events are only sent for jobs that have been added, so that anyone
+	// watching can always look up the job they're told about
+	m.mu.Lock()
+	closed := m.closed
+	if !closed {
+		m.jobs[j.id.String()] = j
+		j.events = m.events
+	}
+	m.mu.Unlock()
+
+	if !closed {
+		m.events.publish(EventCreated, created)
+		m.events.publish(EventStarted, j.info())
+	}
+	if term != nil {
+		go term.copyTo(output.writer(StreamStdout))
+	}
+	go j.wait()
+	if maxRuntime > 0 {
+		go j.timeout()
+	}
+
+	// Shutdown was called while the job was starting, so it won't have
+	// been stopped along with the rest
+	if closed {
+		_ = j.stop(context.Background(), 0, false)
+		return nil, ErrManagerClosed
+	}
+
+	return j.info(), nil
+}
+
+// jobMaxRuntime works out the max runtime for a job that asked for
+// maxRuntime, which is zero if the job didn't ask for one.
+func (m *Manager) jobMaxRuntime(maxRuntime time.Duration) (time.Duration, error) {
+	if maxRuntime < 0 {
+		return 0, fmt.Errorf("%w: max runtime can't be negative", ErrInvalidLimits)
+	}
+	if maxRuntime == 0 {
+		return m.maxRuntime, nil
+	}
+	if m.maxRuntime > 0 && maxRuntime > m.maxRuntime {
+		return 0, fmt.Errorf("%w: max runtime can't be more than %v", ErrInvalidLimits, m.maxRuntime)
+	}
+	return maxRuntime, nil
+}
+
+// StopOption configures how StopJob stops a job.
+type StopOption func(*stopConfig)
+
+// stopConfig holds the settings for stopping a job that can be changed
+// using a StopOption.
+type stopConfig struct {
+	gracePeriod time.Duration
+}
+
+// WithGracePeriod gives the job a chance to clean up before it's
+// stopped. The job is sent SIGTERM, and is only killed using SIGKILL if
+// it's still running once the grace period is over. A grace period of
+// zero or less means the job is killed straight away.
+func WithGracePeriod(gracePeriod time.Duration) StopOption {
+	return func(c *stopConfig) {
+		c.gracePeriod = gracePeriod
+	}
+}
+
+// StopJob stops the job with the given ID, waiting until the process
+// has exited before returning. By default the job is killed straight
+// away, use WithGracePeriod to let it exit cleanly. Stopping a job that
+// has already ended isn't an error, the job info is simply returned.
+//
+// Cancelling ctx only stops StopJob waiting, returning the context
+// error; the job is still stopped, and killed once any grace period is
+// over.
+func (m *Manager) StopJob(ctx context.Context, id string, opts ...StopOption) (*JobInfo, error) {
+	j, err := m.getJob(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var conf stopConfig
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	if err := j.stop(ctx, conf.gracePeriod, false); err != nil {
+		return nil, fmt.Errorf("unable to stop job '%v': %w", id, err)
+	}
+	return j.info(), nil
+}
+
+// Shutdown stops every running job, waiting until they have all exited
+// before returning, and then ends every subscription. Once Shutdown has
+// been called StartJob returns ErrManagerClosed, but the status and
+// output of jobs are still available.
+func (m *Manager) Shutdown() error {
+	m.mu.Lock()
+	m.closed = true
+	jobs := make([]*job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		jobs = append(jobs, j)
+	}
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(jobs))
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			if err := j.stop(context.Background(), 0, false); err != nil {
+				errs <- fmt.Errorf("unable to stop job '%v': %w", j.id, err)
+			}
+		}(j)
+	}
+	wg.Wait()
+	close(errs)
+	// every job has ended, so there won't be any more events
+	m.events.close()
+
+	// every job has been given the chance to stop, so only the first
+	// error is returned
+	return <-errs
+}
+
+// isClosed reports if Shutdown has been called.
+func (m *Manager) isClosed() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.closed
+}
+
+// JobStatus returns the current state of the job with the given ID.
+func (m *Manager) JobStatus(id string) (*JobInfo, error) {
+	j, err := m.getJob(id)
+	if err != nil {
+		return nil, err
+	}
+	return j.info(), nil
+}
+
+// TailOption changes where TailJob starts reading the output of a job,
+// and when it stops.
+type TailOption func(*tailConfig)
+
+// tailConfig holds the settings for tailing a job that can be changed
+// using a TailOption.
+type tailConfig struct {
+	offset int64
+	// lastLines is only used if fromLastLines is set.
+	lastLines     int
+	fromLastLines bool
+	follow        bool
+	// stream is the only stream read, or zero to read both.
+	stream Stream
+}
+
+// WithOffset starts reading the output at the given byte offset, such
+// as the Offset of a reader that was stopped early. The offset can't be
+// past the end of the output written so far.
+func WithOffset(offset int64) TailOption {
+	return func(c *tailConfig) {
+		c.offset = offset
+	}
+}
+
+// WithLastLines starts reading the output at the beginning of the last
+// n lines written so far. Zero means only output written from now on is
+// read. It can't be used with WithOffset.
+func WithLastLines(n int) TailOption {
+	return func(c *tailConfig) {
+		c.lastLines = n
+		c.fromLastLines = true
+	}
+}
+
+// WithFollow sets if the reader waits for more output while the job is
+// running, which it does by default. When follow is false the reader
+// returns io.EOF once it has read the output written before TailJob was
+// called.
+func WithFollow(follow bool) TailOption {
+	return func(c *tailConfig) {
+		c.follow = follow
+	}
+}
+
+// WithStream only reads the output the job wrote to the given stream.
+// By default both stdout and stderr are read, in the order they were
+// captured. Each stream keeps its own order, but they're captured
+// separately, so lines written close together on different streams may
+// be swapped. WithLastLines only counts lines from the given stream, but
+// offsets always count the output of both streams.
+func WithStream(stream Stream) TailOption {
+	return func(c *tailConfig) {
+		c.stream = stream
+	}
+}
+
+// TailJob returns a reader for the output of the job with the given
+// ID. The reader starts at the beginning of the output unless
+// WithOffset or WithLastLines are used; while the job is running calls
+// to Read block until there is more output, and once the job has ended
+// and all output has been read Read returns io.EOF. Cancelling ctx
+// stops the reader.
+//
+// Each call returns a new, independent reader, so any number of callers
+// can tail the same job at once. Callers should Close the reader once
+// they're done with it.
+func (m *Manager) TailJob(ctx context.Context, id string, opts ...TailOption) (*OutputReader, error) {
+	conf := tailConfig{follow: true}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	if conf.offset < 0 {
+		return nil, fmt.Errorf("%w: offset can't be negative", ErrInvalidTail)
+	}
+	if conf.lastLines < 0 {
+		return nil, fmt.Errorf("%w: number of lines can't be negative", ErrInvalidTail)
+	}
+	if conf.offset > 0 && conf.fromLastLines {
+		return nil, fmt.Errorf("%w: can't start from both an offset and the last lines", ErrInvalidTail)
+	}
+	if conf.stream != 0 && conf.stream != StreamStdout && conf.stream != StreamStderr {
+		return nil, fmt.Errorf("%w: unknown stream %v", ErrInvalidTail, conf.stream)
+	}
+
+	j, err := m.getJob(id)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := j.output.tail(ctx, conf)
+	if err != nil {
+		return nil, fmt.Errorf("unable to tail job '%v': %w", id, err)
+	}
+	return r, nil
+}
+
+// startInit starts the init process for a job, calls setup with the
+// PID of the init process, and then waits until the init process has
+// either exec'd the job command or reported an error. The init process
+// doesn't do anything until setup has returned.
+func startInit(cmd *exec.Cmd, setup func(pid int) error) error {
+	errRead, errWrite, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("unable to create init pipe: %w", err)
+	}
+	defer errRead.Close()
+
+	startRead, startWrite, err := os.Pipe()
+	if err != nil {
+		_ = errWrite.Close()
+		return fmt.Errorf("unable to create init pipe: %w", err)
+	}
+	defer startWrite.Close()
+
+	cmd.ExtraFiles = []*os.File{errWrite, startRead}
+
+	err = cmd.Start()
+	_ = errWrite.Close()
+	_ = startRead.Close()
+	if err != nil {
+		return fmt.Errorf("unable to start job init process: %w", err)
+	}
+
+	if err := setup(cmd.Process.Pid); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fmt.Errorf("unable to set up job: %w", err)
+	}
+	_ = startWrite.Close()
+
+	// the write end of the pipe is closed on exec, so reading nothing
+	// means the job command is running
+	msg, err := io.ReadAll(errRead)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fmt.Errorf("unable to read from init pipe: %w", err)
+	}
+	if len(msg) > 0 {
+		_ = cmd.Wait()
+		return initError(msg)
+	}
+	return nil
+}
+
+// initError converts a message read from the init error pipe into an
+// error. Only a job command that can't be found or exec'd is reported
+// as ErrInvalidCommand; anything else is a failure setting up the job.
+func initError(msg []byte) error {
+	if msg[0] == initCommandFailed {
+		return fmt.Errorf("%w: %s", ErrInvalidCommand, msg[1:])
+	}
+	if msg[0] == initSetupFailed {
+		msg = msg[1:]
+	}
+	return fmt.Errorf("unable to set up job: %s", msg)
+}
+
+func (m *Manager) getJob(id string) (*job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%v'", ErrJobNotFound, id)
+	}
+	return j, nil
+}
@@ -0,0 +1,664 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	m, err := NewManager(WithWorkDir(t.TempDir()))
+	require.NoError(t, err)
+	return m
+}
+
+func waitForJob(t *testing.T, m *Manager, id string) *JobInfo {
+	t.Helper()
+	var info *JobInfo
+	require.Eventually(t, func() bool {
+		var err error
+		info, err = m.JobStatus(id)
+		require.NoError(t, err)
+		return info.Status != StatusRunning
+	}, 15*time.Second, 10*time.Millisecond)
+	return info
+}
+
+func TestManager_StartJob(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("cat", []string{"testdata/catme"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, info.ID)
+	assert.Equal(t, "cat", info.Command)
+	assert.Equal(t, []string{"testdata/catme"}, info.Args)
+	assert.False(t, info.StartedAt.IsZero())
+
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, StatusFinished, info.Status)
+	assert.Empty(t, info.ErrorMsg)
+	assert.False(t, info.EndedAt.IsZero())
+}
+
+func TestManager_ExitCode(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("sh", []string{"-c", "exit 3"})
+	require.NoError(t, err)
+
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, StatusFailed, info.Status)
+	assert.Equal(t, 3, info.ExitCode)
+	assert.Zero(t, info.Signal)
+	assert.Equal(t, ReasonExited, info.Reason)
+
+	info, err = m.StartJob("sleep", []string{"30"})
+	require.NoError(t, err)
+	info, err = m.StopJob(context.Background(), info.ID)
+	require.NoError(t, err)
+	assert.Equal(t, -1, info.ExitCode)
+	assert.Equal(t, syscall.SIGKILL, info.Signal)
+	assert.Equal(t, ReasonUserStopped, info.Reason)
+}
+
+func TestManager_KilledBySignal(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("sleep", []string{"30"})
+	require.NoError(t, err)
+	assert.Equal(t, ReasonNone, info.Reason)
+
+	// something other than the manager kills the job
+	m.mu.RLock()
+	pid := m.jobs[info.ID].cmd.Process.Pid
+	m.mu.RUnlock()
+	require.NoError(t, syscall.Kill(pid, syscall.SIGKILL))
+
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, StatusFailed, info.Status)
+	assert.Equal(t, ReasonSignaled, info.Reason)
+	assert.Equal(t, syscall.SIGKILL, info.Signal)
+}
+
+func TestManager_Usage(t *testing.T) {
+	m := newTestManager(t)
+
+	// busy loop for long enough to use a measurable amount of CPU
+	script := `i=0; while [ $i -lt 200000 ]; do i=$((i + 1)); done`
+	info, err := m.StartJob("sh", []string{"-c", script})
+	require.NoError(t, err)
+	assert.Zero(t, info.Usage, "usage is only set once the job ends")
+
+	info = waitForJob(t, m, info.ID)
+	require.Equal(t, StatusFinished, info.Status)
+	assert.Positive(t, info.Usage.UserCPU+info.Usage.SystemCPU)
+	assert.Positive(t, info.Usage.PeakMemoryBytes)
+}
+
+func TestManager_StartJobOwner(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("true", nil, WithOwner("alice"))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", info.Owner)
+
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, "alice", info.Owner)
+}
+
+func TestManager_StartJobInvalid(t *testing.T) {
+	m := newTestManager(t)
+
+	tests := map[string]string{
+		"empty command":   "",
+		"missing command": "not-a-real-command-workernator",
+	}
+
+	for name, cmd := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := m.StartJob(cmd, nil)
+			assert.True(t, errors.Is(err, ErrInvalidCommand), "expected ErrInvalidCommand, got %v", err)
+		})
+	}
+}
+
+func TestInitError(t *testing.T) {
+	tests := map[string]struct {
+		msg         string
+		wantInvalid bool
+	}{
+		"command":   {msg: "Cunable to find 'nope'", wantInvalid: true},
+		"setup":     {msg: "Sunable to set hostname: operation not permitted"},
+		"no prefix": {msg: "something else went wrong"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := initError([]byte(tt.msg))
+			assert.Equal(t, tt.wantInvalid, errors.Is(err, ErrInvalidCommand), "got %v", err)
+			assert.NotContains(t, err.Error(), tt.msg[:1]+"unable")
+		})
+	}
+}
+
+func TestManager_JobFailed(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("cat", []string{"testdata/does-not-exist"})
+	require.NoError(t, err)
+
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, StatusFailed, info.Status)
+	assert.Contains(t, info.ErrorMsg, "exit status 1")
+}
+
+func TestManager_StopJob(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("sleep", []string{"30"})
+	require.NoError(t, err)
+
+	info, err = m.StopJob(context.Background(), info.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusStopped, info.Status)
+	assert.Equal(t, syscall.SIGKILL, info.Signal)
+	assert.False(t, info.EndedAt.IsZero())
+
+	// stopping again just returns the job
+	again, err := m.StopJob(context.Background(), info.ID)
+	require.NoError(t, err)
+	assert.Equal(t, info, again)
+}
+
+func TestManager_StopJobGracePeriod(t *testing.T) {
+	m := newTestManager(t)
+
+	// the job gets to flush its results when asked to stop
+	script := `trap 'echo flushing; exit 0' TERM; echo started; while true; do sleep 0.1; done`
+	info, err := m.StartJob("sh", []string{"-c", script})
+	require.NoError(t, err)
+	waitForOutput(t, m, info.ID, "started\n")
+
+	info, err = m.StopJob(context.Background(), info.ID, WithGracePeriod(10*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, StatusStopped, info.Status)
+	assert.Zero(t, info.Signal, "job exited on its own")
+
+	// the shell may also report on stderr that the sleep it was running
+	// was terminated
+	r, err := m.TailJob(context.Background(), info.ID, WithStream(StreamStdout))
+	require.NoError(t, err)
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "started\nflushing\n", string(out))
+}
+
+func TestManager_StopJobGracePeriodExpired(t *testing.T) {
+	m := newTestManager(t)
+
+	// the job ignores SIGTERM, so it has to be killed
+	script := `trap '' TERM; echo started; while true; do sleep 0.1; done`
+	info, err := m.StartJob("sh", []string{"-c", script})
+	require.NoError(t, err)
+	waitForOutput(t, m, info.ID, "started\n")
+
+	start := time.Now()
+	info, err = m.StopJob(context.Background(), info.ID, WithGracePeriod(300*time.Millisecond))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+	assert.Equal(t, StatusStopped, info.Status)
+	assert.Equal(t, syscall.SIGKILL, info.Signal)
+}
+
+func TestManager_StopJobCancel(t *testing.T) {
+	m := newTestManager(t)
+
+	script := `trap '' TERM; echo started; while true; do sleep 0.1; done`
+	info, err := m.StartJob("sh", []string{"-c", script})
+	require.NoError(t, err)
+	waitForOutput(t, m, info.ID, "started\n")
+
+	// the caller stops waiting, but the job is still killed once the
+	// grace period is over
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = m.StopJob(ctx, info.ID, WithGracePeriod(500*time.Millisecond))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected context.DeadlineExceeded, got %v", err)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, StatusStopped, info.Status)
+	assert.Equal(t, syscall.SIGKILL, info.Signal)
+}
+
+func TestManager_StopJobWhileExiting(t *testing.T) {
+	m := newTestManager(t)
+
+	// a job that ends on its own while being stopped is either stopped
+	// or finished, never a mix of the two
+	for i := 0; i < 20; i++ {
+		info, err := m.StartJob("true", nil)
+		require.NoError(t, err)
+
+		info, err = m.StopJob(context.Background(), info.ID)
+		require.NoError(t, err)
+		switch info.Status {
+		case StatusStopped:
+			assert.Equal(t, ReasonUserStopped, info.Reason)
+		case StatusFinished:
+			assert.Equal(t, ReasonExited, info.Reason)
+			assert.Zero(t, info.Signal)
+		default:
+			t.Fatalf("unexpected status %v", info.Status)
+		}
+	}
+}
+
+func TestManager_StopJobKillsProcessTree(t *testing.T) {
+	m := newTestManager(t)
+
+	// the script forks sleep, which would be left behind if only the
+	// shell was killed
+	info, err := m.StartJob("testdata/slow_output.sh", []string{"tree"})
+	require.NoError(t, err)
+	waitForOutput(t, m, info.ID, "hello tree\n")
+
+	ns := jobPIDNamespace(t, m, info.ID)
+	require.Eventually(t, func() bool {
+		pids, err := namespaceProcesses(ns)
+		require.NoError(t, err)
+		return len(pids) > 1
+	}, 5*time.Second, 10*time.Millisecond, "expected the script and sleep to be running")
+
+	_, err = m.StopJob(context.Background(), info.ID)
+	require.NoError(t, err)
+
+	pids, err := namespaceProcesses(ns)
+	require.NoError(t, err)
+	assert.Empty(t, pids, "processes still running in the job's PID namespace")
+}
+
+func TestManager_StopJobGracePeriodSignalsProcessTree(t *testing.T) {
+	m := newTestManager(t)
+
+	// the shell ignores SIGTERM, but the sleep it started before that
+	// doesn't, so only stops if the signal is sent to the whole job
+	script := `sleep 30 & trap '' TERM; echo started; wait; echo child stopped; while true; do sleep 0.1; done`
+	info, err := m.StartJob("sh", []string{"-c", script})
+	require.NoError(t, err)
+	waitForOutput(t, m, info.ID, "started\n")
+	ns := jobPIDNamespace(t, m, info.ID)
+
+	info, err = m.StopJob(context.Background(), info.ID, WithGracePeriod(500*time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, syscall.SIGKILL, info.Signal)
+
+	r, err := m.TailJob(context.Background(), info.ID)
+	require.NoError(t, err)
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "started\nchild stopped\n", string(out))
+
+	pids, err := namespaceProcesses(ns)
+	require.NoError(t, err)
+	assert.Empty(t, pids, "processes still running in the job's PID namespace")
+}
+
+// jobPIDNamespace returns the PID namespace of a running job.
+func jobPIDNamespace(t *testing.T, m *Manager, id string) string {
+	t.Helper()
+
+	m.mu.RLock()
+	j := m.jobs[id]
+	m.mu.RUnlock()
+	require.NotNil(t, j)
+
+	self, err := pidNamespace(os.Getpid())
+	require.NoError(t, err)
+	require.NotEmpty(t, j.pidNS)
+	require.NotEqual(t, self, j.pidNS, "job should have its own PID namespace")
+	return j.pidNS
+}
+
+// waitForOutput waits until the job has written want to its output.
+func waitForOutput(t *testing.T, m *Manager, id, want string) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	r, err := m.TailJob(ctx, id)
+	require.NoError(t, err)
+	buf := make([]byte, len(want))
+	_, err = io.ReadFull(r, buf)
+	require.NoError(t, err)
+	require.Equal(t, want, string(buf))
+}
+
+func TestManager_Shutdown(t *testing.T) {
+	m := newTestManager(t)
+
+	running, err := m.StartJob("sleep", []string{"30"})
+	require.NoError(t, err)
+	finished, err := m.StartJob("true", nil)
+	require.NoError(t, err)
+	waitForJob(t, m, finished.ID)
+
+	require.NoError(t, m.Shutdown())
+
+	info, err := m.JobStatus(running.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusStopped, info.Status)
+
+	info, err = m.JobStatus(finished.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFinished, info.Status)
+
+	_, err = m.StartJob("true", nil)
+	assert.True(t, errors.Is(err, ErrManagerClosed), "expected ErrManagerClosed, got %v", err)
+}
+
+func TestManager_MaxRuntime(t *testing.T) {
+	m := newTestManager(t)
+
+	// the job gets the same chance to clean up as a job being stopped
+	script := `trap 'echo flushing; exit 0' TERM; while true; do sleep 0.1; done`
+	info, err := m.StartJob("sh", []string{"-c", script}, WithMaxRuntime(300*time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, 300*time.Millisecond, info.MaxRuntime)
+
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, StatusTimedOut, info.Status)
+	assert.Equal(t, ReasonTimeout, info.Reason)
+	assert.GreaterOrEqual(t, info.EndedAt.Sub(info.StartedAt), 300*time.Millisecond)
+
+	// the shell may also report the killed sleep on stderr
+	r, err := m.TailJob(context.Background(), info.ID, WithStream(StreamStdout))
+	require.NoError(t, err)
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(out), "flushing\n"), "unexpected output %q", out)
+}
+
+func TestManager_MaxRuntimeNotReached(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("true", nil, WithMaxRuntime(time.Minute))
+	require.NoError(t, err)
+
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, StatusFinished, info.Status)
+	assert.Equal(t, ReasonExited, info.Reason)
+}
+
+func TestManager_MaxRuntimeCeiling(t *testing.T) {
+	m, err := NewManager(WithWorkDir(t.TempDir()), WithMaxRuntimeCeiling(300*time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = m.StartJob("true", nil, WithMaxRuntime(time.Minute))
+	assert.True(t, errors.Is(err, ErrInvalidLimits), "expected ErrInvalidLimits, got %v", err)
+	_, err = m.StartJob("true", nil, WithMaxRuntime(-time.Second))
+	assert.True(t, errors.Is(err, ErrInvalidLimits), "expected ErrInvalidLimits, got %v", err)
+
+	// jobs that don't ask for a max runtime get the ceiling
+	script := `trap 'exit 0' TERM; while true; do sleep 0.1; done`
+	info, err := m.StartJob("sh", []string{"-c", script})
+	require.NoError(t, err)
+	assert.Equal(t, 300*time.Millisecond, info.MaxRuntime)
+
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, StatusTimedOut, info.Status)
+}
+
+func TestManager_UnknownJob(t *testing.T) {
+	m := newTestManager(t)
+
+	_, err := m.StopJob(context.Background(), "nope")
+	assert.True(t, errors.Is(err, ErrJobNotFound))
+
+	_, err = m.JobStatus("nope")
+	assert.True(t, errors.Is(err, ErrJobNotFound))
+
+	_, err = m.TailJob(context.Background(), "nope")
+	assert.True(t, errors.Is(err, ErrJobNotFound))
+}
+
+func TestManager_TailJob(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("testdata/slow_output.sh", []string{"tester"})
+	require.NoError(t, err)
+
+	r, err := m.TailJob(context.Background(), info.ID)
+	require.NoError(t, err)
+
+	// the first line should show up long before the job is done
+	lines := bufio.NewReader(r)
+	line, err := lines.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "hello tester\n", line)
+
+	status, err := m.JobStatus(info.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRunning, status.Status)
+
+	rest, err := io.ReadAll(lines)
+	require.NoError(t, err)
+	assert.Equal(t, "here i am\nanother line\nboop\nall done!\n", string(rest))
+
+	status, err = m.JobStatus(info.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFinished, status.Status)
+}
+
+func TestManager_TailJobCompleted(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("cat", []string{"testdata/catme"})
+	require.NoError(t, err)
+	waitForJob(t, m, info.ID)
+
+	expect, err := os.ReadFile("testdata/catme")
+	require.NoError(t, err)
+
+	// every reader gets the full output
+	for i := 0; i < 3; i++ {
+		r, err := m.TailJob(context.Background(), info.ID)
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, expect, got)
+	}
+}
+
+func TestManager_TailJobOptions(t *testing.T) {
+	m := newTestManager(t)
+
+	script := `printf 'one\ntwo\nthree\n'; sleep 30`
+	info, err := m.StartJob("sh", []string{"-c", script})
+	require.NoError(t, err)
+	waitForOutput(t, m, info.ID, "one\ntwo\nthree\n")
+	t.Cleanup(func() { _ = m.Shutdown() })
+
+	read := func(opts ...TailOption) string {
+		t.Helper()
+		r, err := m.TailJob(context.Background(), info.ID, append(opts, WithFollow(false))...)
+		require.NoError(t, err)
+		out, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(out)
+	}
+
+	// the job is still running, so these only return because they
+	// don't follow the output
+	assert.Equal(t, "one\ntwo\nthree\n", read())
+	assert.Equal(t, "three\n", read(WithOffset(8)))
+	assert.Equal(t, "two\nthree\n", read(WithLastLines(2)))
+	assert.Equal(t, "", read(WithLastLines(0)))
+
+	invalid := [][]TailOption{
+		{WithOffset(-1)},
+		{WithOffset(100)},
+		{WithLastLines(-1)},
+		{WithOffset(1), WithLastLines(1)},
+		{WithStream(Stream(9))},
+	}
+	for _, opts := range invalid {
+		_, err := m.TailJob(context.Background(), info.ID, opts...)
+		assert.True(t, errors.Is(err, ErrInvalidTail), "expected ErrInvalidTail, got %v", err)
+	}
+}
+
+func TestManager_TailJobStreams(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("sh", []string{"-c", `echo out; echo err >&2; echo more out`})
+	require.NoError(t, err)
+	waitForJob(t, m, info.ID)
+
+	read := func(opts ...TailOption) string {
+		t.Helper()
+		r, err := m.TailJob(context.Background(), info.ID, opts...)
+		require.NoError(t, err)
+		out, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(out)
+	}
+
+	assert.Equal(t, "out\nmore out\n", read(WithStream(StreamStdout)))
+	assert.Equal(t, "err\n", read(WithStream(StreamStderr)))
+	// the streams are copied separately, so when reading both, each keeps
+	// its own order but the stderr line can end up anywhere
+	both := strings.Split(strings.TrimSuffix(read(), "\n"), "\n")
+	assert.ElementsMatch(t, []string{"out", "err", "more out"}, both)
+	var stdout []string
+	for _, line := range both {
+		if line != "err" {
+			stdout = append(stdout, line)
+		}
+	}
+	assert.Equal(t, []string{"out", "more out"}, stdout)
+}
+
+func TestManager_TailJobCancel(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("sleep", []string{"30"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = m.StopJob(context.Background(), info.ID) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	r, err := m.TailJob(ctx, info.ID)
+	require.NoError(t, err)
+
+	_, err = io.ReadAll(r)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected deadline exceeded, got %v", err)
+}
+
+func TestManager_Namespaces(t *testing.T) {
+	m := newTestManager(t)
+
+	script := `hostname; id -u; echo $$; for ns in uts pid mnt net user; do readlink /proc/self/ns/$ns; done`
+	info, err := m.StartJob("sh", []string{"-c", script})
+	require.NoError(t, err)
+	info = waitForJob(t, m, info.ID)
+	require.Equal(t, StatusFinished, info.Status, info.ErrorMsg)
+
+	r, err := m.TailJob(context.Background(), info.ID)
+	require.NoError(t, err)
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	require.Len(t, lines, 8)
+	assert.Equal(t, info.ID, lines[0], "hostname should be the job ID")
+	assert.Equal(t, "0", lines[1], "job should run as root inside the user namespace")
+	assert.Equal(t, "1", lines[2], "job should be PID 1 inside the PID namespace")
+
+	for i, ns := range []string{"uts", "pid", "mnt", "net", "user"} {
+		host, err := os.Readlink("/proc/self/ns/" + ns)
+		require.NoError(t, err)
+		assert.NotEqual(t, host, lines[3+i], "job should be in a new %v namespace", ns)
+	}
+}
+
+func TestManager_JobIDs(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("only root can map jobs to other users")
+	}
+
+	// a file only root on the host can read, in a directory anyone can
+	// reach
+	dir := t.TempDir()
+	require.NoError(t, os.Chmod(dir, 0o755)) //nolint:gosec // the job has to reach the file
+	secret := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(secret, []byte("secret"), 0o000))
+
+	for name, opts := range map[string][]Option{
+		"default":  nil,
+		"explicit": {WithJobIDs(1234, 5678)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewManager(append([]Option{WithWorkDir(t.TempDir())}, opts...)...)
+			require.NoError(t, err)
+
+			// root inside the job isn't root on the host
+			info, err := m.StartJob("sh", []string{"-c", "cat /proc/self/uid_map /proc/self/gid_map; cat " + secret})
+			require.NoError(t, err)
+			info = waitForJob(t, m, info.ID)
+			assert.Equal(t, StatusFailed, info.Status)
+
+			uid, gid := nobodyID, nobodyID
+			if opts != nil {
+				uid, gid = 1234, 5678
+			}
+			r, err := m.TailJob(context.Background(), info.ID, WithStream(StreamStdout))
+			require.NoError(t, err)
+			out, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"0", fmt.Sprint(uid), "1", "0", fmt.Sprint(gid), "1"}, strings.Fields(string(out)))
+		})
+	}
+
+	_, err := NewManager(WithJobIDs(0, 0))
+	assert.Error(t, err)
+}
+
+func TestManager_TailJobConcurrent(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("testdata/slow_output.sh", []string{"everyone"})
+	require.NoError(t, err)
+
+	expect := "hello everyone\nhere i am\nanother line\nboop\nall done!\n"
+	results := make(chan string)
+	for i := 0; i < 5; i++ {
+		r, err := m.TailJob(context.Background(), info.ID)
+		require.NoError(t, err)
+
+		go func() {
+			got, _ := io.ReadAll(r)
+			results <- string(got)
+		}()
+	}
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, expect, <-results)
+	}
+}
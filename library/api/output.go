@@ -0,0 +1,486 @@
+package api
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// errOutputClosed is returned when writing to an output that has been
+// closed.
+var errOutputClosed = errors.New("output closed")
+
+// indexSuffix is added to the path of an output file to get the path of
+// its index.
+const indexSuffix = ".index"
+
+// Stream is which of the output streams of a job some output came from.
+type Stream uint8
+
+const (
+	// StreamStdout is output the job wrote to stdout.
+	StreamStdout Stream = iota + 1
+	// StreamStderr is output the job wrote to stderr.
+	StreamStderr
+)
+
+// String returns a human-readable version of the stream.
+func (s Stream) String() string {
+	switch s {
+	case StreamStdout:
+		return "stdout"
+	case StreamStderr:
+		return "stderr"
+	}
+	return "unknown"
+}
+
+// indexRecord describes a single write to an output: where it is in the
+// output file, which stream it came from, and when it was captured.
+type indexRecord struct {
+	offset int64
+	length int64
+	stream Stream
+	at     time.Time
+}
+
+// indexRecordSize is the size of an encoded indexRecord.
+const indexRecordSize = 8 + 8 + 1 + 8
+
+// end returns the offset just past the data the record describes.
+func (rec indexRecord) end() int64 {
+	return rec.offset + rec.length
+}
+
+func (rec indexRecord) marshal() []byte {
+	buf := make([]byte, indexRecordSize)
+	binary.BigEndian.PutUint64(buf[0:8], uint64(rec.offset))
+	binary.BigEndian.PutUint64(buf[8:16], uint64(rec.length))
+	buf[16] = byte(rec.stream)
+	binary.BigEndian.PutUint64(buf[17:25], uint64(rec.at.UnixNano()))
+	return buf
+}
+
+func unmarshalIndexRecord(buf []byte) indexRecord {
+	return indexRecord{
+		offset: int64(binary.BigEndian.Uint64(buf[0:8])),
+		length: int64(binary.BigEndian.Uint64(buf[8:16])),
+		stream: Stream(buf[16]),
+		at:     time.Unix(0, int64(binary.BigEndian.Uint64(buf[17:25]))),
+	}
+}
+
+// output stores the output of a job in a file, along with an index
+// recording which stream each write came from and when, so that the
+// order stdout and stderr were written in is kept without changing the
+// output itself. Any number of readers can follow
+// along as it's written, each with its own file handles so a slow reader
+// never holds up the job or any other reader.
+type output struct {
+	path  string
+	file  *os.File
+	index *os.File
+
+	mu sync.Mutex
+	// cond is signalled whenever more data is written, or the output is
+	// closed.
+	cond *sync.Cond
+	size int64
+	// records is how many records have been written to the index.
+	records int64
+	closed  bool
+}
+
+// newOutput creates the file at path to store the output in, and the
+// index next to it.
+func newOutput(path string) (*output, error) {
+	// jobs run as a different user, so they mustn't be able to read the
+	// output of other jobs
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600) //nolint:gosec // path is built by the manager
+	if err != nil {
+		return nil, fmt.Errorf("unable to create output file: %w", err)
+	}
+	idx, err := os.OpenFile(path+indexSuffix, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600) //nolint:gosec // path is built by the manager
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("unable to create output index: %w", err)
+	}
+
+	o := &output{path: path, file: f, index: idx}
+	o.cond = sync.NewCond(&o.mu)
+	return o, nil
+}
+
+// writer returns a writer that records everything written to it as
+// coming from stream.
+func (o *output) writer(stream Stream) io.Writer {
+	return streamWriter{output: o, stream: stream}
+}
+
+// streamWriter is an io.Writer for one of the streams of an output.
+type streamWriter struct {
+	output *output
+	stream Stream
+}
+
+// Write implements io.Writer.
+func (w streamWriter) Write(p []byte) (int, error) {
+	return w.output.write(w.stream, p)
+}
+
+// write appends p to the output file and records it in the index, then
+// wakes up any waiting readers. Data only counts as written once its
+// index record has been written, so a failed write is overwritten by the
+// next one.
+func (o *output) write(stream Stream, p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return 0, errOutputClosed
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	n, err := o.file.WriteAt(p, o.size)
+	if n == 0 {
+		return 0, err
+	}
+
+	rec := indexRecord{offset: o.size, length: int64(n), stream: stream, at: time.Now()}
+	if _, ierr := o.index.WriteAt(rec.marshal(), o.records*indexRecordSize); ierr != nil {
+		return 0, fmt.Errorf("unable to write output index: %w", ierr)
+	}
+	o.size += int64(n)
+	o.records++
+	o.cond.Broadcast()
+	return n, err
+}
+
+// Close closes the output file and index. Readers return io.EOF once
+// they've read everything that was written before Close was called.
+func (o *output) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return nil
+	}
+	o.closed = true
+	o.cond.Broadcast()
+	err := o.file.Close()
+	if ierr := o.index.Close(); err == nil {
+		err = ierr
+	}
+	return err
+}
+
+// reader returns a new reader that starts at the beginning of the
+// output and reads both streams. Cancelling ctx stops the reader,
+// causing Read to return the context error.
+func (o *output) reader(ctx context.Context) (*OutputReader, error) {
+	f, err := os.Open(o.path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open output file: %w", err)
+	}
+	idx, err := os.Open(o.path + indexSuffix)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("unable to open output index: %w", err)
+	}
+
+	r := &OutputReader{
+		output: o,
+		ctx:    ctx,
+		file:   f,
+		index:  idx,
+		limit:  -1,
+		done:   make(chan struct{}),
+	}
+
+	// sync.Cond can't wait on a context, so wake up all the readers if
+	// this one's context is cancelled; the rest go back to waiting
+	go func() {
+		select {
+		case <-ctx.Done():
+			o.mu.Lock()
+			o.cond.Broadcast()
+			o.mu.Unlock()
+		case <-r.done:
+		}
+	}()
+
+	return r, nil
+}
+
+// tail returns a new reader that starts where conf says to. The start
+// is worked out using the output written so far.
+func (o *output) tail(ctx context.Context, conf tailConfig) (*OutputReader, error) {
+	r, err := o.reader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.stream = conf.stream
+
+	o.mu.Lock()
+	size, records := o.size, o.records
+	o.mu.Unlock()
+
+	switch {
+	case conf.fromLastLines:
+		if err := r.lastLines(conf.lastLines, size, records); err != nil {
+			return nil, r.finish(fmt.Errorf("unable to find last lines of output: %w", err))
+		}
+	case conf.offset > size:
+		return nil, r.finish(fmt.Errorf("%w: offset %v is past the end of the output", ErrInvalidTail, conf.offset))
+	default:
+		if err := r.seek(conf.offset, records); err != nil {
+			return nil, r.finish(fmt.Errorf("unable to find offset in output: %w", err))
+		}
+	}
+
+	if !conf.follow {
+		r.limit = records
+	}
+	return r, nil
+}
+
+// Chunk is a piece of the output of a job that all came from the same
+// stream, and was captured at the same time.
+type Chunk struct {
+	Stream Stream
+	// Offset is the offset of the start of Data in the output of the job,
+	// counting both streams.
+	Offset int64
+	Data   []byte
+	// At is when the job wrote Data.
+	At time.Time
+}
+
+// OutputReader reads the output of a job, blocking until there is more
+// output or the job has ended.
+type OutputReader struct {
+	output *output
+	ctx    context.Context
+	file   *os.File
+	index  *os.File
+	offset int64
+	// record is the number of the index record offset is in, or the
+	// number of the next record to be written if everything has been
+	// read.
+	record int64
+	// limit is the number of records the reader stops after, or -1 to
+	// keep reading until the output is closed.
+	limit int64
+	// stream is the only stream read, or zero to read both.
+	stream Stream
+
+	done chan struct{}
+	err  error
+}
+
+// Offset returns the offset in the output of the next byte that could
+// be read, which can be used to carry on from the same place later.
+// Offsets count the output of both streams, even if only one is being
+// read.
+func (r *OutputReader) Offset() int64 {
+	return r.offset
+}
+
+// Read implements io.Reader.
+func (r *OutputReader) Read(p []byte) (int, error) {
+	chunk, err := r.ReadChunk(p)
+	return len(chunk.Data), err
+}
+
+// ReadChunk reads up to len(p) bytes of output into p, like Read, but
+// never returns data from more than one write at once. The chunk
+// returned says which stream the data came from, where it is in the
+// output, and when it was written.
+func (r *OutputReader) ReadChunk(p []byte) (Chunk, error) {
+	if r.err != nil {
+		return Chunk{}, r.err
+	}
+	if len(p) == 0 {
+		return Chunk{}, nil
+	}
+
+	for {
+		if err := r.wait(); err != nil {
+			return Chunk{}, r.finish(err)
+		}
+
+		rec, err := r.readRecord(r.record)
+		if err != nil {
+			return Chunk{}, r.finish(fmt.Errorf("unable to read output index: %w", err))
+		}
+		if r.offset < rec.offset {
+			r.offset = rec.offset
+		}
+		if !r.wants(rec.stream) {
+			r.offset = rec.end()
+		}
+		if r.offset >= rec.end() {
+			r.record++
+			continue
+		}
+
+		if available := rec.end() - r.offset; int64(len(p)) > available {
+			p = p[:available]
+		}
+		n, err := r.file.ReadAt(p, r.offset)
+		chunk := Chunk{Stream: rec.stream, Offset: r.offset, Data: p[:n], At: rec.at}
+		r.offset += int64(n)
+		if r.offset >= rec.end() {
+			r.record++
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return chunk, r.finish(fmt.Errorf("unable to read output: %w", err))
+		}
+		return chunk, nil
+	}
+}
+
+// wants returns true if output from stream should be read.
+func (r *OutputReader) wants(stream Stream) bool {
+	return r.stream == 0 || r.stream == stream
+}
+
+// readRecord reads the index record with the given number.
+func (r *OutputReader) readRecord(n int64) (indexRecord, error) {
+	buf := make([]byte, indexRecordSize)
+	if _, err := r.index.ReadAt(buf, n*indexRecordSize); err != nil {
+		return indexRecord{}, err
+	}
+	return unmarshalIndexRecord(buf), nil
+}
+
+// seek moves the reader to offset, using the first records index records
+// to find the record it's in.
+func (r *OutputReader) seek(offset, records int64) error {
+	lo, hi := int64(0), records
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		rec, err := r.readRecord(mid)
+		if err != nil {
+			return err
+		}
+		if rec.end() <= offset {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	r.offset, r.record = offset, lo
+	return nil
+}
+
+// lastLines moves the reader to the start of the last n lines of the
+// first size bytes of output, which are described by the first records
+// index records. Only output from the stream being read is counted. A
+// newline at the very end doesn't start a new line.
+func (r *OutputReader) lastLines(n int, size, records int64) error {
+	if n == 0 {
+		r.offset, r.record = size, records
+		return nil
+	}
+
+	buf := make([]byte, 4096)
+	last := true
+	for i := records - 1; i >= 0; i-- {
+		rec, err := r.readRecord(i)
+		if err != nil {
+			return err
+		}
+		if !r.wants(rec.stream) {
+			continue
+		}
+
+		end := rec.end()
+		for end > rec.offset {
+			start := end - int64(len(buf))
+			if start < rec.offset {
+				start = rec.offset
+			}
+			chunk := buf[:end-start]
+			if _, err := r.file.ReadAt(chunk, start); err != nil {
+				return err
+			}
+
+			for j := len(chunk) - 1; j >= 0; j-- {
+				// skip the newline ending the last line, if there is one
+				if last {
+					last = false
+					if chunk[j] == '\n' {
+						continue
+					}
+				}
+				if chunk[j] != '\n' {
+					continue
+				}
+				n--
+				if n == 0 {
+					r.offset, r.record = start+int64(j)+1, i
+					return nil
+				}
+			}
+			end = start
+		}
+	}
+
+	r.offset, r.record = 0, 0
+	return nil
+}
+
+// wait blocks until there is an index record for the reader to read. It
+// returns io.EOF if the output is closed or the limit has been reached,
+// and everything has been read.
+func (r *OutputReader) wait() error {
+	if r.limit >= 0 && r.record >= r.limit {
+		return io.EOF
+	}
+
+	o := r.output
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for o.records <= r.record {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		if o.closed {
+			return io.EOF
+		}
+		o.cond.Wait()
+	}
+	return nil
+}
+
+// Close releases the resources held by the reader. It's safe to call
+// once Read has returned an error, which has already released them, and
+// calling it more than once is fine. After Close, Read returns
+// os.ErrClosed. Close mustn't be called while a call to Read is blocked;
+// cancel the context passed to TailJob to stop it instead.
+func (r *OutputReader) Close() error {
+	if r.err == nil {
+		_ = r.finish(os.ErrClosed)
+	}
+	return nil
+}
+
+// finish releases the resources held by the reader, and makes sure
+// every future call to Read returns err.
+func (r *OutputReader) finish(err error) error {
+	r.err = err
+	close(r.done)
+	_ = r.file.Close()
+	_ = r.index.Close()
+	return err
+}
@@ -0,0 +1,363 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOutput(t *testing.T) *output {
+	t.Helper()
+	o, err := newOutput(filepath.Join(t.TempDir(), "output"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = o.Close() })
+	return o
+}
+
+func TestOutput_ReaderWaitsForData(t *testing.T) {
+	o := newTestOutput(t)
+
+	r, err := o.reader(context.Background())
+	require.NoError(t, err)
+
+	got := make(chan string)
+	go func() {
+		buf := make([]byte, 64)
+		n, _ := r.Read(buf)
+		got <- string(buf[:n])
+	}()
+
+	select {
+	case data := <-got:
+		t.Fatalf("read returned '%v' before anything was written", data)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	_, err = o.write(StreamStdout, []byte("hello"))
+	require.NoError(t, err)
+
+	select {
+	case data := <-got:
+		assert.Equal(t, "hello", data)
+	case <-time.After(time.Second):
+		t.Fatal("read didn't wake up after a write")
+	}
+}
+
+func TestOutput_ReaderEOF(t *testing.T) {
+	o := newTestOutput(t)
+
+	_, err := o.write(StreamStdout, []byte("some output"))
+	require.NoError(t, err)
+
+	r, err := o.reader(context.Background())
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	var got []byte
+	go func() {
+		defer close(done)
+		got, err = io.ReadAll(r)
+	}()
+
+	_, err2 := o.write(StreamStdout, []byte(", more output"))
+	require.NoError(t, err2)
+	require.NoError(t, o.Close())
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("reader didn't finish after the output was closed")
+	}
+	require.NoError(t, err)
+	assert.Equal(t, "some output, more output", string(got))
+
+	// readers created after the output is closed get everything too
+	r, err = o.reader(context.Background())
+	require.NoError(t, err)
+	got, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "some output, more output", string(got))
+
+	_, err = o.write(StreamStdout, []byte("too late"))
+	assert.True(t, errors.Is(err, errOutputClosed))
+}
+
+func TestOutput_ReaderCancel(t *testing.T) {
+	o := newTestOutput(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r, err := o.reader(ctx)
+	require.NoError(t, err)
+
+	// a second reader that isn't cancelled should keep waiting
+	other, err := o.reader(context.Background())
+	require.NoError(t, err)
+	otherGot := make(chan string)
+	go func() {
+		buf := make([]byte, 64)
+		n, _ := other.Read(buf)
+		otherGot <- string(buf[:n])
+	}()
+
+	errs := make(chan error)
+	go func() {
+		_, err := r.Read(make([]byte, 64))
+		errs <- err
+	}()
+
+	cancel()
+	select {
+	case err := <-errs:
+		assert.True(t, errors.Is(err, context.Canceled), "expected context.Canceled, got %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("read didn't return after the context was cancelled")
+	}
+
+	_, err = o.write(StreamStdout, []byte("still here"))
+	require.NoError(t, err)
+	assert.Equal(t, "still here", <-otherGot)
+}
+
+func TestOutputReader_Close(t *testing.T) {
+	o := newTestOutput(t)
+
+	_, err := o.write(StreamStdout, []byte("some output"))
+	require.NoError(t, err)
+
+	r, err := o.reader(context.Background())
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	n, err := r.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "some", string(buf[:n]))
+
+	require.NoError(t, r.Close())
+	_, err = r.Read(buf)
+	assert.True(t, errors.Is(err, os.ErrClosed), "expected os.ErrClosed, got %v", err)
+	_, err = r.file.Read(buf)
+	assert.True(t, errors.Is(err, os.ErrClosed), "expected the output file to be closed, got %v", err)
+	assert.NoError(t, r.Close())
+
+	// closing a reader that has already finished keeps the error it
+	// finished with
+	require.NoError(t, o.Close())
+	r, err = o.reader(context.Background())
+	require.NoError(t, err)
+	_, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.NoError(t, r.Close())
+	_, err = r.Read(buf)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestOutput_ConcurrentReaders(t *testing.T) {
+	o := newTestOutput(t)
+
+	var expect bytes.Buffer
+	readers := 10
+	results := make([][]byte, readers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < readers; i++ {
+		r, err := o.reader(context.Background())
+		require.NoError(t, err)
+
+		wg.Add(1)
+		go func(i int, r io.Reader) {
+			defer wg.Done()
+			// reading a byte at a time makes some readers much slower
+			// than the writer
+			buf := make([]byte, 1+i*100)
+			var out bytes.Buffer
+			for {
+				n, err := r.Read(buf)
+				out.Write(buf[:n])
+				if err != nil {
+					break
+				}
+			}
+			results[i] = out.Bytes()
+		}(i, r)
+	}
+
+	for i := 0; i < 1000; i++ {
+		line := []byte(time.Now().String() + "\n")
+		expect.Write(line)
+		_, err := o.write(StreamStdout, line)
+		require.NoError(t, err)
+	}
+	require.NoError(t, o.Close())
+	wg.Wait()
+
+	for i, got := range results {
+		assert.Equal(t, expect.String(), string(got), "reader %v got the wrong output", i)
+	}
+}
+
+func TestOutputReader_LastLines(t *testing.T) {
+	tests := map[string]struct {
+		data   string
+		lines  int
+		expect int64
+	}{
+		"none":                  {"a\nb\nc\n", 0, 6},
+		"one":                   {"a\nb\nc\n", 1, 4},
+		"two":                   {"a\nb\nc\n", 2, 2},
+		"all":                   {"a\nb\nc\n", 3, 0},
+		"more than there is":    {"a\nb\nc\n", 10, 0},
+		"no trailing newline":   {"a\nb\nc", 1, 4},
+		"empty lines":           {"a\n\n\n", 2, 2},
+		"empty":                 {"", 5, 0},
+		"longer than the chunk": {string(bytes.Repeat([]byte("x"), 5000)) + "\nlast\n", 1, 5001},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			o := newTestOutput(t)
+			_, err := o.write(StreamStdout, []byte(tt.data))
+			require.NoError(t, err)
+
+			r, err := o.tail(context.Background(), tailConfig{lastLines: tt.lines, fromLastLines: true})
+			require.NoError(t, err)
+			defer func() { _ = r.finish(io.EOF) }()
+			assert.Equal(t, tt.expect, r.Offset())
+		})
+	}
+}
+
+func TestOutput_Tail(t *testing.T) {
+	o := newTestOutput(t)
+	_, err := o.write(StreamStdout, []byte("one\ntwo\nthree\n"))
+	require.NoError(t, err)
+
+	// without following, only what's there already is read
+	r, err := o.tail(context.Background(), tailConfig{offset: 4})
+	require.NoError(t, err)
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "two\nthree\n", string(out))
+	assert.Equal(t, int64(14), r.Offset())
+
+	r, err = o.tail(context.Background(), tailConfig{lastLines: 1, fromLastLines: true, follow: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), r.Offset())
+
+	_, err = o.write(StreamStdout, []byte("four\n"))
+	require.NoError(t, err)
+	require.NoError(t, o.Close())
+	out, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "three\nfour\n", string(out))
+
+	_, err = o.tail(context.Background(), tailConfig{offset: 100})
+	assert.True(t, errors.Is(err, ErrInvalidTail), "expected ErrInvalidTail, got %v", err)
+}
+
+func TestOutput_Streams(t *testing.T) {
+	o := newTestOutput(t)
+	stdout, stderr := o.writer(StreamStdout), o.writer(StreamStderr)
+
+	for _, w := range []struct {
+		w    io.Writer
+		data string
+	}{
+		{stdout, "out one\n"},
+		{stderr, "err one\n"},
+		{stdout, "out two\n"},
+		{stderr, "err two\n"},
+	} {
+		_, err := w.w.Write([]byte(w.data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, o.Close())
+
+	// reading both streams keeps the order they were written in, and
+	// chunks never mix streams
+	r, err := o.reader(context.Background())
+	require.NoError(t, err)
+	var chunks []Chunk
+	for {
+		chunk, err := r.ReadChunk(make([]byte, 64))
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		// the capture times are checked in TestOutput_Timestamps
+		assert.False(t, chunk.At.IsZero())
+		chunk.At = time.Time{}
+		chunks = append(chunks, chunk)
+	}
+	assert.Equal(t, []Chunk{
+		{Stream: StreamStdout, Offset: 0, Data: []byte("out one\n")},
+		{Stream: StreamStderr, Offset: 8, Data: []byte("err one\n")},
+		{Stream: StreamStdout, Offset: 16, Data: []byte("out two\n")},
+		{Stream: StreamStderr, Offset: 24, Data: []byte("err two\n")},
+	}, chunks)
+
+	tests := map[string]struct {
+		conf   tailConfig
+		expect string
+	}{
+		"stdout":                {tailConfig{stream: StreamStdout}, "out one\nout two\n"},
+		"stderr":                {tailConfig{stream: StreamStderr}, "err one\nerr two\n"},
+		"both":                  {tailConfig{}, "out one\nerr one\nout two\nerr two\n"},
+		"offset in other":       {tailConfig{stream: StreamStderr, offset: 2}, "err one\nerr two\n"},
+		"offset in same":        {tailConfig{stream: StreamStdout, offset: 4}, "one\nout two\n"},
+		"last line of stdout":   {tailConfig{stream: StreamStdout, lastLines: 1, fromLastLines: true}, "out two\n"},
+		"last lines of stderr":  {tailConfig{stream: StreamStderr, lastLines: 2, fromLastLines: true}, "err one\nerr two\n"},
+		"last lines of both":    {tailConfig{lastLines: 2, fromLastLines: true}, "out two\nerr two\n"},
+		"no last lines":         {tailConfig{stream: StreamStdout, lastLines: 0, fromLastLines: true}, ""},
+		"more lines than exist": {tailConfig{stream: StreamStdout, lastLines: 5, fromLastLines: true}, "out one\nout two\n"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := o.tail(context.Background(), tt.conf)
+			require.NoError(t, err)
+			out, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, string(out))
+			assert.Equal(t, int64(32), r.Offset())
+		})
+	}
+}
+
+func TestOutput_Timestamps(t *testing.T) {
+	o := newTestOutput(t)
+
+	before := time.Now()
+	_, err := o.write(StreamStdout, []byte("first"))
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	between := time.Now()
+	_, err = o.write(StreamStdout, []byte("second"))
+	require.NoError(t, err)
+	after := time.Now()
+	require.NoError(t, o.Close())
+
+	r, err := o.reader(context.Background())
+	require.NoError(t, err)
+
+	// chunks never span writes, even if there's room in the buffer
+	first, err := r.ReadChunk(make([]byte, 64))
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(first.Data))
+	assert.False(t, first.At.Before(before.Truncate(0)), "first write captured before it was written")
+	assert.False(t, first.At.After(between.Truncate(0)), "first write captured after it was written")
+
+	second, err := r.ReadChunk(make([]byte, 64))
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(second.Data))
+	assert.False(t, second.At.Before(between.Truncate(0)), "second write captured before it was written")
+	assert.False(t, second.At.After(after.Truncate(0)), "second write captured after it was written")
+}
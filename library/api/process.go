@@ -0,0 +1,40 @@
+package api
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// pidNamespace returns the PID namespace of the process with the given
+// PID, in the form used by the kernel for the ns/pid link, ie
+// 'pid:[4026531836]'.
+func pidNamespace(pid int) (string, error) {
+	ns, err := os.Readlink("/proc/" + strconv.Itoa(pid) + "/ns/pid")
+	if err != nil {
+		return "", fmt.Errorf("unable to read PID namespace of process %v: %w", pid, err)
+	}
+	return ns, nil
+}
+
+// namespaceProcesses returns the PIDs, as seen from this process, of
+// every process in the PID namespace ns.
+func namespaceProcesses(ns string) ([]int, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list processes: %w", err)
+	}
+
+	var pids []int
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		// the process may have exited since /proc was read
+		if got, err := pidNamespace(pid); err == nil && got == ns {
+			pids = append(pids, pid)
+		}
+	}
+	return pids, nil
+}
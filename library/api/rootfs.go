@@ -0,0 +1,249 @@
+package api
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+)
+
+// xz support needs a dependency, as the standard library doesn't have
+// it, but busybox.tar, the root filesystem shipped with the repo, is xz
+// compressed despite its name.
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	xzMagic   = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+)
+
+// rootFSDirName is the name of the directory inside the job directory
+// that the root filesystem is unpacked into.
+const rootFSDirName = "rootfs"
+
+// WithRootFS sets the path to a tarball ( optionally gzip or xz
+// compressed ) that contains the root filesystem jobs run inside. The
+// tarball is unpacked into a new directory for each job. If this option
+// isn't provided, jobs see the same filesystem as the manager.
+func WithRootFS(tarball string) Option {
+	return func(m *Manager) error {
+		st, err := os.Stat(tarball)
+		if err != nil {
+			return fmt.Errorf("unable to use root filesystem '%v': %w", tarball, err)
+		}
+		if st.IsDir() {
+			return fmt.Errorf("unable to use root filesystem '%v': path is a directory, not a tarball", tarball)
+		}
+		if err := checkTarball(tarball); err != nil {
+			return fmt.Errorf("unable to use root filesystem '%v': %w", tarball, err)
+		}
+		m.rootFS = tarball
+		return nil
+	}
+}
+
+// checkTarball makes sure the file at path is a tarball by reading the
+// first header from it.
+func checkTarball(path string) error {
+	f, err := os.Open(path) //nolint:gosec // path is provided by whoever configured the manager
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r, err := decompress(bufio.NewReader(f))
+	if err != nil {
+		return err
+	}
+	if _, err := tar.NewReader(r).Next(); err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("tarball is empty")
+		}
+		return fmt.Errorf("not a tarball: %w", err)
+	}
+	return nil
+}
+
+// unpackRootFS unpacks the tarball at path into the dest directory,
+// with everything owned by uid and gid so that it belongs to root inside
+// the job.
+func unpackRootFS(path, dest string, uid, gid int) error {
+	f, err := os.Open(path) //nolint:gosec // path is provided by whoever configured the manager
+	if err != nil {
+		return fmt.Errorf("unable to open root filesystem tarball: %w", err)
+	}
+	defer f.Close()
+
+	r, err := decompress(bufio.NewReader(f))
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dest, 0o755); err != nil { //nolint:gosec // the rootfs needs to be readable by the job
+		return fmt.Errorf("unable to create root filesystem directory: %w", err)
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return chownTree(dest, uid, gid)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read from root filesystem tarball: %w", err)
+		}
+
+		if err := unpackEntry(tr, header, dest); err != nil {
+			return err
+		}
+	}
+}
+
+// decompress checks the magic bytes at the start of the tarball, and
+// wraps it in the appropriate decompressor if it's gzip or xz
+// compressed.
+func decompress(br *bufio.Reader) (io.Reader, error) {
+	magic, err := br.Peek(len(xzMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unable to read root filesystem tarball: %w", err)
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open root filesystem tarball with gzip: %w", err)
+		}
+		return gz, nil
+
+	case bytes.HasPrefix(magic, xzMagic):
+		xr, err := xz.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open root filesystem tarball with xz: %w", err)
+		}
+		return xr, nil
+	}
+
+	return br, nil
+}
+
+// unpackEntry creates a single entry from the tarball inside dest.
+func unpackEntry(tr *tar.Reader, header *tar.Header, dest string) error {
+	target, err := entryPath(dest, header.Name)
+	if err != nil {
+		return err
+	}
+	mode := os.FileMode(header.Mode).Perm()
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		if err := os.MkdirAll(target, mode); err != nil {
+			return fmt.Errorf("unable to create directory '%v': %w", header.Name, err)
+		}
+		// MkdirAll won't change the mode of a directory that exists, and
+		// the umask may have changed it, so set it explicitly
+		if err := os.Chmod(target, mode); err != nil {
+			return fmt.Errorf("unable to set mode of directory '%v': %w", header.Name, err)
+		}
+
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil { //nolint:gosec // the rootfs needs to be readable by the job
+			return fmt.Errorf("unable to create directory for '%v': %w", header.Name, err)
+		}
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+		if err != nil {
+			return fmt.Errorf("unable to create file '%v': %w", header.Name, err)
+		}
+		// the size comes from a tarball the admin chose to use
+		if _, err := io.Copy(out, tr); err != nil { //nolint:gosec // see above
+			_ = out.Close()
+			return fmt.Errorf("unable to write file '%v': %w", header.Name, err)
+		}
+		if err := out.Close(); err != nil {
+			return fmt.Errorf("unable to write file '%v': %w", header.Name, err)
+		}
+
+	case tar.TypeSymlink:
+		// symlinks are resolved inside the job once it has pivoted into
+		// the root filesystem, so they're created as-is
+		if err := os.Symlink(header.Linkname, target); err != nil {
+			return fmt.Errorf("unable to create symlink '%v': %w", header.Name, err)
+		}
+
+	case tar.TypeLink:
+		source, err := entryPath(dest, header.Linkname)
+		if err != nil {
+			return err
+		}
+		if err := os.Link(source, target); err != nil {
+			return fmt.Errorf("unable to create hard link '%v': %w", header.Name, err)
+		}
+	}
+
+	// anything else ( devices, fifos, etc ) is skipped; the devices a job
+	// needs are bind mounted in from the host when the job starts
+	return nil
+}
+
+// chownTree changes the owner of dir and everything inside it, without
+// following symlinks.
+func chownTree(dir string, uid, gid int) error {
+	err := filepath.WalkDir(dir, func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, uid, gid)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to change owner of root filesystem: %w", err)
+	}
+	return nil
+}
+
+// entryPath returns the path name will have once unpacked into dest,
+// making sure that nothing already unpacked along that path, including
+// the path itself, is a symlink. Otherwise an entry such as 'a/passwd'
+// after a symlink 'a -> /etc' would be written outside dest.
+func entryPath(dest, name string) (string, error) {
+	target, err := rootFSPath(dest, name)
+	if err != nil {
+		return "", err
+	}
+
+	path := dest
+	for _, part := range strings.Split(strings.TrimPrefix(target, dest), string(os.PathSeparator)) {
+		if part == "" {
+			continue
+		}
+		path = filepath.Join(path, part)
+		st, err := os.Lstat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			// nothing below here has been unpacked yet
+			return target, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("unable to check path '%v' in root filesystem: %w", name, err)
+		}
+		if st.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("invalid path in root filesystem tarball: '%v' goes through a symlink", name)
+		}
+	}
+	return target, nil
+}
+
+// rootFSPath returns the path name will have once unpacked into dest,
+// making sure that path doesn't escape dest.
+func rootFSPath(dest, name string) (string, error) {
+	target := filepath.Join(dest, filepath.Clean("/"+name))
+	if target != dest && !strings.HasPrefix(target, dest+string(os.PathSeparator)) {
+		return "", fmt.Errorf("invalid path in root filesystem tarball: '%v'", name)
+	}
+	return target, nil
+}
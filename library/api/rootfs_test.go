@@ -0,0 +1,220 @@
+package api
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRootFS is xz compressed, despite the name.
+const testRootFS = "../../busybox.tar"
+
+// newRootFSManager builds a manager that runs jobs inside testRootFS.
+// t.TempDir can't be used for the work directory, as it's private to
+// the user running the tests and jobs run as someone else, so a new
+// directory is left for WithWorkDir to create in the system temporary
+// directory instead.
+func newRootFSManager(t *testing.T) *Manager {
+	t.Helper()
+
+	dir := filepath.Join(os.TempDir(), "workernator-test-"+xid.New().String())
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	m, err := NewManager(WithWorkDir(dir), WithRootFS(testRootFS))
+	require.NoError(t, err)
+	return m
+}
+
+func TestUnpackRootFS(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "rootfs")
+	require.NoError(t, unpackRootFS(testRootFS, dest, 1234, 5678))
+
+	for _, dir := range []string{"bin", "etc", "tmp", "usr/sbin"} {
+		st, err := os.Stat(filepath.Join(dest, dir))
+		require.NoError(t, err)
+		assert.True(t, st.IsDir(), "expected '%v' to be a directory", dir)
+	}
+
+	// busybox uses hard links for every command
+	sh, err := os.Stat(filepath.Join(dest, "bin/sh"))
+	require.NoError(t, err)
+	ifup, err := os.Stat(filepath.Join(dest, "bin/ifup"))
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(sh, ifup))
+	assert.NotZero(t, sh.Mode()&0o111, "expected bin/sh to be executable")
+
+	// everything belongs to root inside the job
+	if os.Getuid() == 0 {
+		for _, path := range []string{"", "bin", "bin/sh"} {
+			st, err := os.Lstat(filepath.Join(dest, path))
+			require.NoError(t, err)
+			sys := st.Sys().(*syscall.Stat_t)
+			assert.Equal(t, []uint32{1234, 5678}, []uint32{sys.Uid, sys.Gid}, path)
+		}
+	}
+}
+
+func TestUnpackRootFSEscape(t *testing.T) {
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret")
+	require.NoError(t, os.WriteFile(secret, []byte("secret"), 0o600))
+
+	tests := map[string][]*tar.Header{
+		"file through symlink": {
+			{Name: "a", Typeflag: tar.TypeSymlink, Linkname: outside},
+			{Name: "a/passwd", Typeflag: tar.TypeReg, Mode: 0o644},
+		},
+		"file over symlink": {
+			{Name: "a", Typeflag: tar.TypeSymlink, Linkname: secret},
+			{Name: "a", Typeflag: tar.TypeReg, Mode: 0o644},
+		},
+		"directory over symlink": {
+			{Name: "a", Typeflag: tar.TypeSymlink, Linkname: outside},
+			{Name: "a", Typeflag: tar.TypeDir, Mode: 0o777},
+		},
+		"directory through symlink": {
+			{Name: "a", Typeflag: tar.TypeSymlink, Linkname: outside},
+			{Name: "a/b/", Typeflag: tar.TypeDir, Mode: 0o755},
+		},
+		"hard link through symlink": {
+			{Name: "a", Typeflag: tar.TypeSymlink, Linkname: outside},
+			{Name: "b", Typeflag: tar.TypeLink, Linkname: "a/secret"},
+		},
+		"hard link into symlink": {
+			{Name: "bin/", Typeflag: tar.TypeDir, Mode: 0o755},
+			{Name: "bin/sh", Typeflag: tar.TypeReg, Mode: 0o755},
+			{Name: "a", Typeflag: tar.TypeSymlink, Linkname: outside},
+			{Name: "a/sh", Typeflag: tar.TypeLink, Linkname: "bin/sh"},
+		},
+	}
+
+	for name, headers := range tests {
+		t.Run(name, func(t *testing.T) {
+			tarball := writeTarball(t, headers)
+			dest := filepath.Join(t.TempDir(), "rootfs")
+
+			err := unpackRootFS(tarball, dest, os.Getuid(), os.Getgid())
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), "goes through a symlink")
+			}
+
+			// nothing outside the root filesystem was touched
+			entries, err := os.ReadDir(outside)
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			st, err := os.Stat(secret)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o600), st.Mode())
+			data, err := os.ReadFile(secret)
+			require.NoError(t, err)
+			assert.Equal(t, "secret", string(data))
+		})
+	}
+}
+
+// writeTarball writes a tarball containing empty entries with the given
+// headers, returning its path.
+func writeTarball(t *testing.T, headers []*tar.Header) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "rootfs.tar")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	tw := tar.NewWriter(f)
+	for _, header := range headers {
+		require.NoError(t, tw.WriteHeader(header))
+	}
+	require.NoError(t, tw.Close())
+	return path
+}
+
+func TestRootFSPath(t *testing.T) {
+	tests := map[string]string{
+		"./bin/sh":       "/root/bin/sh",
+		"etc/passwd":     "/root/etc/passwd",
+		"../../etc/foo":  "/root/etc/foo",
+		"/usr/../../tmp": "/root/tmp",
+		"./":             "/root",
+	}
+
+	for name, expect := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := rootFSPath("/root", name)
+			require.NoError(t, err)
+			assert.Equal(t, expect, got)
+		})
+	}
+}
+
+func TestWithRootFSInvalid(t *testing.T) {
+	// catme is a plain text file
+	_, err := NewManager(WithWorkDir(t.TempDir()), WithRootFS("testdata/catme"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not a tarball")
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.tar")
+	require.NoError(t, os.WriteFile(empty, nil, 0o600))
+	_, err = NewManager(WithWorkDir(t.TempDir()), WithRootFS(empty))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "empty")
+	}
+
+	_, err = NewManager(WithWorkDir(t.TempDir()), WithRootFS("testdata"))
+	assert.Error(t, err)
+
+	// jobs have to be able to reach the root filesystem, which they
+	// can't inside a private directory when running as someone else
+	private := t.TempDir()
+	require.NoError(t, os.Chmod(private, 0o700))
+	_, err = NewManager(WithWorkDir(private), WithRootFS(testRootFS), WithJobIDs(1234, 1234))
+	assert.Error(t, err)
+}
+
+func TestManager_RootFS(t *testing.T) {
+	m := newRootFSManager(t)
+
+	script := `ls /; echo ---; cat /proc/1/comm; echo ---; grep -E ' /(proc|tmp) ' /proc/mounts | cut -d' ' -f2,3; echo ok > /dev/null`
+	info, err := m.StartJob("/bin/sh", []string{"-c", script})
+	require.NoError(t, err)
+	info = waitForJob(t, m, info.ID)
+	require.Equal(t, StatusFinished, info.Status, info.ErrorMsg)
+
+	r, err := m.TailJob(context.Background(), info.ID)
+	require.NoError(t, err)
+	out, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	parts := strings.Split(string(out), "---\n")
+	require.Len(t, parts, 3, string(out))
+
+	root := strings.Fields(parts[0])
+	assert.ElementsMatch(t, []string{"bin", "dev", "etc", "home", "lib", "lib64", "proc", "root", "tmp", "usr", "var"}, root)
+	assert.Equal(t, "sh\n", parts[1], "/proc should belong to the job PID namespace")
+	assert.Equal(t, "/proc proc\n/tmp tmpfs\n", parts[2])
+
+	// the unpacked root filesystem is cleaned up once the job ends
+	m.mu.RLock()
+	j := m.jobs[info.ID]
+	m.mu.RUnlock()
+	_, err = os.Stat(j.rootfs)
+	assert.True(t, os.IsNotExist(err), "expected rootfs to be removed, got %v", err)
+}
+
+func TestManager_RootFSInvalidCommand(t *testing.T) {
+	m := newRootFSManager(t)
+
+	// the command has to exist inside the root filesystem, not the host
+	_, err := m.StartJob("testdata/slow_output.sh", nil)
+	assert.ErrorIs(t, err, ErrInvalidCommand)
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalType is the TERM set for jobs that have a terminal.
+const terminalType = "xterm"
+
+// terminal is the pseudo-terminal a job was started with.
+type terminal struct {
+	master *os.File
+	// copied is closed once everything the job wrote to the terminal has
+	// been copied to the output.
+	copied chan struct{}
+
+	mu sync.Mutex
+	// closed is set once master has been closed.
+	closed bool
+}
+
+// openTerminal opens a new pseudo-terminal, returning the side to pass
+// to the job as its stdin, stdout, and stderr. The caller must close it
+// once the job has started.
+//
+// The terminal comes from the host's /dev/ptmx rather than the devpts
+// instance setupRootFS mounts for the job, as the manager needs the
+// master side before the job is started, and jobs without a root
+// filesystem don't get their own instance. The job only ever sees the
+// terminal through the file descriptors it's given and /dev/tty; it
+// isn't listed in the job's /dev/pts.
+func openTerminal() (*terminal, *os.File, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to open terminal: %w", err)
+	}
+
+	// the job side has to be unlocked before it can be opened
+	var n int
+	err = control(master, func(fd int) error {
+		if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+			return err
+		}
+		n, err = unix.IoctlGetInt(fd, unix.TIOCGPTN)
+		return err
+	})
+	if err != nil {
+		_ = master.Close()
+		return nil, nil, fmt.Errorf("unable to set up terminal: %w", err)
+	}
+
+	path := fmt.Sprintf("/dev/pts/%d", n)
+	slave, err := os.OpenFile(path, os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		_ = master.Close()
+		return nil, nil, fmt.Errorf("unable to open terminal: %w", err)
+	}
+
+	return &terminal{master: master, copied: make(chan struct{})}, slave, nil
+}
+
+// setup makes slave the stdin, stdout, and stderr of cmd, as well as the
+// controlling terminal of the new session the job runs in.
+func (t *terminal) setup(cmd *exec.Cmd, slave *os.File) {
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+	cmd.Env = append(cmd.Env, initTermEnv+"="+terminalType)
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+	// Ctty is the file descriptor in the job, so stdin
+	cmd.SysProcAttr.Ctty = 0
+}
+
+// input returns a new handle on the terminal for writing input to, so
+// that closing the input doesn't close the terminal.
+func (t *terminal) input() (*input, error) {
+	var dup int
+	err := control(t.master, func(fd int) (err error) {
+		dup, err = unix.FcntlInt(uintptr(fd), unix.F_DUPFD_CLOEXEC, 0)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open terminal input: %w", err)
+	}
+	return &input{file: os.NewFile(uintptr(dup), t.master.Name()), terminal: true}, nil
+}
+
+// copyTo copies everything the job writes to the terminal to w. It
+// returns once every process in the job has closed the terminal.
+func (t *terminal) copyTo(w io.Writer) {
+	defer close(t.copied)
+	_, err := io.Copy(w, t.master)
+	// reading fails with EIO rather than returning EOF once the other
+	// side has been closed
+	if err != nil && !errors.Is(err, syscall.EIO) {
+		_, _ = fmt.Fprintf(w, "\r\nworkernator: unable to read terminal: %v\r\n", err)
+	}
+}
+
+// resize sets the window size of the terminal, returning os.ErrClosed
+// once the terminal has been closed.
+func (t *terminal) resize(rows, cols uint16) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return os.ErrClosed
+	}
+	return control(t.master, func(fd int) error {
+		return unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Row: rows, Col: cols})
+	})
+}
+
+// close closes the terminal once everything has been copied from it.
+func (t *terminal) close() {
+	<-t.copied
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	_ = t.master.Close()
+}
+
+// control calls fn with the file descriptor of f. Unlike f.Fd, it
+// doesn't put the file into blocking mode.
+func control(f *os.File, fn func(fd int) error) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var fnErr error
+	if err := conn.Control(func(fd uintptr) { fnErr = fn(int(fd)) }); err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// ResizeTerminal sets the window size of the terminal of the job with
+// the given ID, which must have been started using WithTTY.
+// ErrNoTerminal is returned if the job doesn't have a terminal, or has
+// ended.
+func (m *Manager) ResizeTerminal(id string, rows, cols uint16) error {
+	j, err := m.getJob(id)
+	if err != nil {
+		return err
+	}
+	if j.terminal == nil {
+		return fmt.Errorf("%w: job '%v' wasn't started with a terminal", ErrNoTerminal, id)
+	}
+
+	if err := j.terminal.resize(rows, cols); err != nil {
+		if errors.Is(err, os.ErrClosed) {
+			return fmt.Errorf("%w: job '%v' has ended", ErrNoTerminal, id)
+		}
+		return fmt.Errorf("unable to resize terminal of job '%v': %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_TTY(t *testing.T) {
+	m := newTestManager(t)
+
+	script := `read line; echo "got $line"; [ -t 1 ] && echo "is a tty"; stty size; echo "TERM=$TERM"`
+	info, err := m.StartJob("sh", []string{"-c", script}, WithTTY())
+	require.NoError(t, err)
+	assert.True(t, info.TTY)
+
+	require.NoError(t, m.ResizeTerminal(info.ID, 40, 120))
+
+	// the terminal stays open once the input has been sent
+	_, err = m.SendInput(context.Background(), info.ID, strings.NewReader("hello\n"))
+	require.NoError(t, err)
+
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, StatusFinished, info.Status, info.ErrorMsg)
+
+	// the terminal echoes the input, and turns newlines into CRLF
+	out := readAll(t, m, info.ID)
+	assert.Equal(t, "hello\r\ngot hello\r\nis a tty\r\n40 120\r\nTERM="+terminalType+"\r\n", out)
+
+	err = m.ResizeTerminal(info.ID, 40, 120)
+	assert.True(t, errors.Is(err, ErrNoTerminal), "expected ErrNoTerminal, got %v", err)
+}
+
+func TestManager_TTYEndOfFile(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("cat", nil, WithTTY())
+	require.NoError(t, err)
+
+	_, err = m.SendInput(context.Background(), info.ID, strings.NewReader("one\n"))
+	require.NoError(t, err)
+	// cat only exits once it's sent an end-of-file character
+	_, err = m.SendInput(context.Background(), info.ID, strings.NewReader("\x04"))
+	require.NoError(t, err)
+
+	info = waitForJob(t, m, info.ID)
+	assert.Equal(t, StatusFinished, info.Status, info.ErrorMsg)
+	assert.Equal(t, "one\r\none\r\n", readAll(t, m, info.ID))
+}
+
+func TestManager_ResizeTerminalWithoutTTY(t *testing.T) {
+	m := newTestManager(t)
+
+	info, err := m.StartJob("sleep", []string{"30"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = m.StopJob(context.Background(), info.ID) })
+	assert.False(t, info.TTY)
+
+	err = m.ResizeTerminal(info.ID, 40, 120)
+	assert.True(t, errors.Is(err, ErrNoTerminal), "expected ErrNoTerminal, got %v", err)
+
+	err = m.ResizeTerminal("nope", 40, 120)
+	assert.True(t, errors.Is(err, ErrJobNotFound), "expected ErrJobNotFound, got %v", err)
+}
+
+func TestManager_TTYRootFS(t *testing.T) {
+	m := newRootFSManager(t)
+
+	// /dev/tty is the controlling terminal of the job
+	info, err := m.StartJob("/bin/sh", []string{"-c", "echo hello > /dev/tty"}, WithTTY())
+	require.NoError(t, err)
+	info = waitForJob(t, m, info.ID)
+	require.Equal(t, StatusFinished, info.Status, info.ErrorMsg)
+	assert.Equal(t, "hello\r\n", readAll(t, m, info.ID))
+
+	// the job has its own /dev/pts, so none of the host terminals,
+	// including the one the job was started with, are in it
+	info, err = m.StartJob("/bin/sh", []string{"-c", "echo /dev/pts/*"}, WithTTY())
+	require.NoError(t, err)
+	info = waitForJob(t, m, info.ID)
+	require.Equal(t, StatusFinished, info.Status, info.ErrorMsg)
+	assert.Equal(t, "/dev/pts/ptmx\r\n", readAll(t, m, info.ID))
+}
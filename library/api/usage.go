@@ -0,0 +1,46 @@
+package api
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// rusageBlockSize is the size of the blocks counted by the inblock and
+// oublock fields of rusage.
+const rusageBlockSize = 512
+
+// ResourceUsage is how much of the system's resources a job used.
+type ResourceUsage struct {
+	// UserCPU is the CPU time spent running the job's code.
+	UserCPU time.Duration
+	// SystemCPU is the CPU time the kernel spent on behalf of the job.
+	SystemCPU time.Duration
+	// PeakMemoryBytes is the most memory the job used at once. With a
+	// cgroup this comes from memory.peak and covers every process in the
+	// job, otherwise it's the largest resident set size of any single
+	// process.
+	PeakMemoryBytes int64
+	// IOReadBytes and IOWriteBytes are how many bytes the job read from
+	// and wrote to block devices.
+	IOReadBytes  int64
+	IOWriteBytes int64
+}
+
+// processUsage gets the resource usage of a process that has exited,
+// which includes any descendants it waited for.
+func processUsage(state *os.ProcessState) ResourceUsage {
+	usage := ResourceUsage{
+		UserCPU:   state.UserTime(),
+		SystemCPU: state.SystemTime(),
+	}
+
+	if ru, ok := state.SysUsage().(*syscall.Rusage); ok {
+		// ru_maxrss is in kilobytes on Linux
+		usage.PeakMemoryBytes = ru.Maxrss * 1024
+		usage.IOReadBytes = ru.Inblock * rusageBlockSize
+		usage.IOWriteBytes = ru.Oublock * rusageBlockSize
+	}
+
+	return usage
+}
@@ -0,0 +1,737 @@
+// Package client is the Go client for workernator. It wraps the GRPC
+// API so that users of the package don't need to know anything about
+// GRPC or the generated protobuf types.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/seanhagen/workernator/internal/pb"
+	"github.com/seanhagen/workernator/internal/tlsconfig"
+)
+
+// Option configures a Client.
+type Option func(*config)
+
+// config holds the settings used to connect to the server.
+type config struct {
+	rootCert string
+	cert     string
+	key      string
+}
+
+// WithRootCert sets the path to the CA certificate used to verify the
+// server certificate. This option is required.
+func WithRootCert(path string) Option {
+	return func(c *config) {
+		c.rootCert = path
+	}
+}
+
+// WithCert sets the path to the client certificate used to identify
+// the user to the server. This option is required.
+func WithCert(path string) Option {
+	return func(c *config) {
+		c.cert = path
+	}
+}
+
+// WithKey sets the path to the private key for the client certificate.
+// This option is required.
+func WithKey(path string) Option {
+	return func(c *config) {
+		c.key = path
+	}
+}
+
+// Client talks to a workernator server.
+type Client struct {
+	conn    *grpc.ClientConn
+	service pb.ServiceClient
+}
+
+// New builds a client for the server at addr. The root certificate,
+// client certificate, and key must all be provided using options, as
+// the server only accepts connections using mTLS.
+//
+// No connection is made until the first request, so a server that
+// can't be reached results in ErrUnavailable from that request.
+func New(addr string, opts ...Option) (*Client, error) {
+	var conf config
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	if conf.rootCert == "" || conf.cert == "" || conf.key == "" {
+		return nil, fmt.Errorf("%w: root certificate, certificate, and key are all required", ErrMissingCredentials)
+	}
+
+	creds, err := tlsconfig.ClientCredentials(tlsconfig.Config{
+		RootCert: conf.rootCert,
+		Cert:     conf.cert,
+		Key:      conf.key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("unable to set up connection to '%v': %w", addr, err)
+	}
+
+	return &Client{conn: conn, service: pb.NewServiceClient(conn)}, nil
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// StartOption configures a job started by Start.
+type StartOption func(*startConfig)
+
+// startConfig holds the request sent by Start, so that options can
+// set fields on it.
+type startConfig struct {
+	req *pb.JobStartRequest
+}
+
+// WithLimits sets the resource limits for the job. Any fields left as
+// zero use the defaults configured on the server.
+func WithLimits(limits Limits) StartOption {
+	return func(c *startConfig) {
+		c.req.Limits = limitsToPB(limits)
+	}
+}
+
+// WithMaxRuntime sets how long the job is allowed to run before the
+// server stops it. Without it the maximum configured on the server is
+// used.
+func WithMaxRuntime(maxRuntime time.Duration) StartOption {
+	return func(c *startConfig) {
+		c.req.MaxRuntime = durationpb.New(maxRuntime)
+	}
+}
+
+// WithStdin keeps the stdin of the job open so that input can be sent
+// to it using SendInput. Without it the job reads EOF from stdin
+// straight away.
+func WithStdin() StartOption {
+	return func(c *startConfig) {
+		c.req.Stdin = true
+	}
+}
+
+// WithTTY runs the job with a terminal as its stdin, stdout, and stderr,
+// so that it can be used interactively using Attach. Everything the job
+// writes is sent as stdout.
+func WithTTY() StartOption {
+	return func(c *startConfig) {
+		c.req.Tty = true
+	}
+}
+
+// Start starts a job running command with the given arguments.
+func (c *Client) Start(ctx context.Context, command string, args []string, opts ...StartOption) (*Job, error) {
+	conf := startConfig{req: &pb.JobStartRequest{Command: command, Arguments: args}}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	job, err := c.service.Start(ctx, conf.req)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return jobFromPB(job), nil
+}
+
+// inputChunkSize is the maximum number of bytes sent in each message
+// streamed by SendInput.
+const inputChunkSize = 32 * 1024
+
+// SendInput sends everything read from r to the stdin of a job, which
+// must have been started using WithStdin. Once r returns io.EOF the stdin
+// of the job is closed, so the job reads EOF as well. If reading r fails,
+// everything read before that is still written and stdin is left open,
+// so the rest of the input can be sent by calling SendInput again. If ctx
+// is cancelled stdin is also left open, but input sent just before may
+// not have been written.
+//
+// The number of bytes the server wrote to stdin is returned, unless the
+// connection to the server failed. ErrNoInput is returned if the job
+// isn't accepting input.
+func (c *Client) SendInput(ctx context.Context, id string, r io.Reader) (int64, error) {
+	stream, err := c.service.Input(ctx)
+	if err != nil {
+		return 0, convertError(err)
+	}
+
+	// the ID is sent by itself first, so that the server can reject the
+	// request before anything has been read from r
+	req := &pb.JobInputRequest{Id: id}
+	buf := make([]byte, inputChunkSize)
+	for {
+		if err := stream.Send(req); err != nil {
+			// the reason the stream ended is returned by CloseAndRecv
+			_, err = stream.CloseAndRecv()
+			return 0, convertError(err)
+		}
+
+		n, err := r.Read(buf)
+		if errors.Is(err, io.EOF) && n == 0 {
+			break
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return c.endInput(stream, buf[:n], fmt.Errorf("unable to read input: %w", err))
+		}
+		req = &pb.JobInputRequest{Data: buf[:n]}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return 0, convertError(err)
+	}
+	return resp.GetBytesWritten(), nil
+}
+
+// endInput sends the last of the input read before readErr, asking the
+// server to leave stdin open, and returns readErr along with how much
+// the server wrote.
+func (c *Client) endInput(stream pb.Service_InputClient, data []byte, readErr error) (int64, error) {
+	if err := stream.Send(&pb.JobInputRequest{Data: data, KeepOpen: true}); err != nil {
+		_, err = stream.CloseAndRecv()
+		return 0, convertError(err)
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return 0, convertError(err)
+	}
+	return resp.GetBytesWritten(), readErr
+}
+
+// WindowSize is the size of a terminal, in characters.
+type WindowSize struct {
+	Rows uint16
+	Cols uint16
+}
+
+// AttachOption configures Attach.
+type AttachOption func(*attachConfig)
+
+// attachConfig holds the settings for Attach that can be changed using
+// an AttachOption.
+type attachConfig struct {
+	sizes <-chan WindowSize
+}
+
+// WithWindowSizes resizes the terminal of the job to every size received
+// from sizes, such as the size of the local terminal whenever it changes.
+func WithWindowSizes(sizes <-chan WindowSize) AttachOption {
+	return func(c *attachConfig) {
+		c.sizes = sizes
+	}
+}
+
+// Attach connects to the terminal of a job, which must have been started
+// using WithTTY. Everything read from in is sent to the job, and
+// everything the job writes to its terminal from then on is written to
+// out. Attach returns once the job has ended, or once in returns io.EOF,
+// which detaches from the job and leaves it running. If reading in fails
+// Attach also detaches, and returns the error.
+//
+// in carries on being read in the background until it returns, even
+// after Attach has returned. ErrNoInput is returned if the job doesn't
+// have a terminal, or another client is attached to it.
+func (c *Client) Attach(ctx context.Context, id string, in io.Reader, out io.Writer, opts ...AttachOption) error {
+	var conf attachConfig
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.service.Attach(ctx)
+	if err != nil {
+		return convertError(err)
+	}
+
+	sendErr := make(chan error, 1)
+	go func() { sendErr <- sendAttach(ctx, stream, id, in, conf.sizes) }()
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return convertError(err)
+		}
+		if _, err := out.Write(resp.GetOutput()); err != nil {
+			return fmt.Errorf("unable to write output: %w", err)
+		}
+	}
+
+	// the stream has ended because either the job ended, or the input
+	// did; only the input failing is an error
+	cancel()
+	return <-sendErr
+}
+
+// sendAttach sends the ID of the job to attach to, then the input read
+// from in along with any changes to the window size, until in returns an
+// error or ctx is cancelled. in returning io.EOF isn't an error.
+func sendAttach(ctx context.Context, stream pb.Service_AttachClient, id string, in io.Reader, sizes <-chan WindowSize) error {
+	// errors sending are ignored, as the reason the stream ended is
+	// returned by Recv
+	if err := stream.Send(&pb.AttachRequest{Id: id}); err != nil {
+		return nil
+	}
+
+	// reading can block forever, so it's done separately to sending the
+	// window size changes
+	input, readErr := make(chan []byte), make(chan error, 1)
+	go func() {
+		for {
+			buf := make([]byte, inputChunkSize)
+			n, err := in.Read(buf)
+			if n > 0 {
+				select {
+				case input <- buf[:n]:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		req := &pb.AttachRequest{}
+		select {
+		case data := <-input:
+			req.Input = data
+		case size, ok := <-sizes:
+			if !ok {
+				sizes = nil
+				continue
+			}
+			req.Resize = &pb.WindowSize{Rows: uint32(size.Rows), Cols: uint32(size.Cols)}
+		case err := <-readErr:
+			_ = stream.CloseSend()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("unable to read input: %w", err)
+		case <-ctx.Done():
+			return nil
+		}
+
+		if err := stream.Send(req); err != nil {
+			return nil
+		}
+	}
+}
+
+// StopOption configures how Stop stops a job.
+type StopOption func(*stopConfig)
+
+// stopConfig holds the request sent by Stop, so that options can set
+// fields on it.
+type stopConfig struct {
+	req *pb.JobStopRequest
+}
+
+// WithGracePeriod gives the job a chance to exit cleanly. The job is
+// sent SIGTERM, and is only killed with SIGKILL if it's still running
+// once the grace period is over.
+func WithGracePeriod(gracePeriod time.Duration) StopOption {
+	return func(c *stopConfig) {
+		c.req.GracePeriod = durationpb.New(gracePeriod)
+	}
+}
+
+// Stop stops a job, returning once it has stopped. By default the job
+// is killed immediately, use WithGracePeriod to let it exit cleanly.
+func (c *Client) Stop(ctx context.Context, id string, opts ...StopOption) (*Job, error) {
+	conf := stopConfig{req: &pb.JobStopRequest{Id: id}}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	job, err := c.service.Stop(ctx, conf.req)
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return jobFromPB(job), nil
+}
+
+// Status returns the current state of a job.
+func (c *Client) Status(ctx context.Context, id string) (*Job, error) {
+	job, err := c.service.Status(ctx, &pb.JobStatusRequest{Id: id})
+	if err != nil {
+		return nil, convertError(err)
+	}
+	return jobFromPB(job), nil
+}
+
+// ListOption filters or pages the jobs returned by List.
+type ListOption func(*listConfig)
+
+// listConfig holds the request sent by List, so that options can set
+// fields on it.
+type listConfig struct {
+	req *pb.JobListRequest
+}
+
+// WithStatuses only returns jobs in any of the given states.
+func WithStatuses(statuses ...JobStatus) ListOption {
+	return func(c *listConfig) {
+		for _, s := range statuses {
+			c.req.Statuses = append(c.req.Statuses, statusToPB[s])
+		}
+	}
+}
+
+// WithOwner only returns jobs started by the given user.
+func WithOwner(owner string) ListOption {
+	return func(c *listConfig) {
+		c.req.Owner = owner
+	}
+}
+
+// WithCommand only returns jobs running exactly the given command.
+func WithCommand(command string) ListOption {
+	return func(c *listConfig) {
+		c.req.Command = command
+	}
+}
+
+// WithStartedAfter only returns jobs started at or after t.
+func WithStartedAfter(t time.Time) ListOption {
+	return func(c *listConfig) {
+		c.req.StartedAfter = timestamppb.New(t)
+	}
+}
+
+// WithStartedBefore only returns jobs started before t.
+func WithStartedBefore(t time.Time) ListOption {
+	return func(c *listConfig) {
+		c.req.StartedBefore = timestamppb.New(t)
+	}
+}
+
+// WithPageSize sets the most jobs returned by List. Without it the
+// server's default is used, and the server caps how large it can be.
+func WithPageSize(size int) ListOption {
+	return func(c *listConfig) {
+		c.req.PageSize = int32(size)
+	}
+}
+
+// WithPageToken gets the page of jobs after the page that returned
+// token.
+func WithPageToken(token string) ListOption {
+	return func(c *listConfig) {
+		c.req.PageToken = token
+	}
+}
+
+// List returns a page of the jobs that match the options, in the order
+// they were started, along with the token for the next page. The token
+// is empty once there are no more jobs.
+func (c *Client) List(ctx context.Context, opts ...ListOption) ([]*Job, string, error) {
+	conf := listConfig{req: &pb.JobListRequest{}}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	resp, err := c.service.List(ctx, conf.req)
+	if err != nil {
+		return nil, "", convertError(err)
+	}
+
+	jobs := make([]*Job, 0, len(resp.GetJobs()))
+	for _, job := range resp.GetJobs() {
+		jobs = append(jobs, jobFromPB(job))
+	}
+	return jobs, resp.GetNextPageToken(), nil
+}
+
+// WatchOption filters the events sent by Watch.
+type WatchOption func(*watchConfig)
+
+// watchConfig holds the request sent by Watch, so that options can set
+// fields on it.
+type watchConfig struct {
+	req   *pb.JobWatchRequest
+	ready func()
+}
+
+// WithWatchIDs only sends events for the jobs with the given IDs.
+func WithWatchIDs(ids ...string) WatchOption {
+	return func(c *watchConfig) {
+		c.req.Ids = append(c.req.Ids, ids...)
+	}
+}
+
+// WithWatchOwner only sends events for jobs started by the given user.
+func WithWatchOwner(owner string) WatchOption {
+	return func(c *watchConfig) {
+		c.req.Owner = owner
+	}
+}
+
+// WithReady calls ready once the server is sending events, after which
+// no events will be missed.
+func WithReady(ready func()) WatchOption {
+	return func(c *watchConfig) {
+		c.ready = ready
+	}
+}
+
+// Watch calls handle for every event that happens to a job, in the
+// order they happen, until ctx is cancelled or handle returns an error.
+// Only events that happen after Watch is called are sent. If the server
+// decides events aren't being handled quickly enough, an error wrapping
+// ErrTooSlow is returned.
+func (c *Client) Watch(ctx context.Context, handle func(Event) error, opts ...WatchOption) error {
+	conf := watchConfig{req: &pb.JobWatchRequest{}}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	stream, err := c.service.Watch(ctx, conf.req)
+	if err != nil {
+		return convertError(err)
+	}
+	// any error is returned by Recv as well
+	if _, err := stream.Header(); err == nil && conf.ready != nil {
+		conf.ready()
+	}
+
+	for {
+		ev, err := stream.Recv()
+		if err != nil {
+			// the stream only ends when there's an error
+			return convertError(err)
+		}
+		if err := handle(eventFromPB(ev)); err != nil {
+			return err
+		}
+	}
+}
+
+// Stream is one of the output streams of a job.
+type Stream int
+
+const (
+	// StreamStdout is the output a job writes to stdout.
+	StreamStdout Stream = iota + 1
+	// StreamStderr is the output a job writes to stderr.
+	StreamStderr
+)
+
+// String returns the name of the stream.
+func (s Stream) String() string {
+	switch s {
+	case StreamStdout:
+		return "stdout"
+	case StreamStderr:
+		return "stderr"
+	}
+	return "unknown"
+}
+
+// streamToPB maps client output streams to their GRPC equivalent.
+var streamToPB = map[Stream]pb.OutputStream{
+	StreamStdout: pb.OutputStream_Stdout,
+	StreamStderr: pb.OutputStream_Stderr,
+}
+
+// streamFromPB maps GRPC output streams to their client equivalent.
+var streamFromPB = map[pb.OutputStream]Stream{
+	pb.OutputStream_Stdout: StreamStdout,
+	pb.OutputStream_Stderr: StreamStderr,
+}
+
+// Chunk is a piece of the output of a job that all came from the same
+// stream.
+type Chunk struct {
+	Stream Stream
+	// Offset is the offset of the start of Data in the output of the job,
+	// counting both streams.
+	Offset int64
+	Data   []byte
+	// At is when the job wrote Data. It's only set when using
+	// WithTimestamps.
+	At time.Time
+}
+
+// chunkFromPB converts a GRPC output response into a Chunk.
+func chunkFromPB(resp *pb.OutputJobResponse) Chunk {
+	chunk := Chunk{
+		Stream: streamFromPB[resp.GetStream()],
+		Offset: resp.GetOffset(),
+		Data:   resp.GetData(),
+	}
+	if resp.GetTime() != nil {
+		chunk.At = resp.GetTime().AsTime()
+	}
+	return chunk
+}
+
+// TailOption changes where Tail and TailTo start reading the output of a
+// job, and when they stop.
+type TailOption func(*tailConfig)
+
+// tailConfig holds the request sent by Tail and TailTo, so that options
+// can set fields on it.
+type tailConfig struct {
+	req *pb.OutputJobRequest
+	// stderr is where output from stderr is written, if it's not written
+	// to the same place as stdout.
+	stderr io.Writer
+}
+
+// WithOffset starts the output at the given byte offset, such as the
+// offset returned by TailTo when it was stopped early.
+func WithOffset(offset int64) TailOption {
+	return func(c *tailConfig) {
+		c.req.StartOffset = offset
+	}
+}
+
+// WithLastLines starts the output at the beginning of the last n lines
+// written so far. Zero means only output written from now on is sent.
+// It can't be used with WithOffset.
+func WithLastLines(n int) TailOption {
+	return func(c *tailConfig) {
+		lines := int32(n)
+		c.req.TailLines = &lines
+	}
+}
+
+// WithFollow sets if TailTo keeps writing new output until the job ends,
+// which it does by default. When follow is false TailTo returns once the
+// output written so far has been written to w.
+func WithFollow(follow bool) TailOption {
+	return func(c *tailConfig) {
+		c.req.Follow = &follow
+	}
+}
+
+// WithStream only writes the output the job wrote to the given stream.
+// By default both stdout and stderr are written, in the order they were
+// captured. Each stream keeps its own order, but lines the job wrote close
+// together on different streams may be swapped.
+func WithStream(stream Stream) TailOption {
+	return func(c *tailConfig) {
+		c.req.Stream = streamToPB[stream]
+	}
+}
+
+// WithTimestamps sets if the time the job wrote each chunk of output is
+// sent along with it, so that Chunk.At is set. It doesn't change the
+// output itself, so TailTo ignores it.
+func WithTimestamps(timestamps bool) TailOption {
+	return func(c *tailConfig) {
+		c.req.Timestamps = timestamps
+	}
+}
+
+// WithStderrTo writes the output the job wrote to stderr to w, instead
+// of to the writer passed to TailTo.
+func WithStderrTo(w io.Writer) TailOption {
+	return func(c *tailConfig) {
+		c.stderr = w
+	}
+}
+
+// Tail passes the output of a job to handle a chunk at a time, by
+// default from the beginning until the job has ended and all of its
+// output has been handled. Cancel ctx to stop early. If handle returns
+// an error, Tail stops and returns it.
+//
+// The offset just past the last chunk handled is always returned, even
+// with an error, so that WithOffset can be used to carry on from the
+// same place if the connection to the server was lost.
+func (c *Client) Tail(ctx context.Context, id string, handle func(Chunk) error, opts ...TailOption) (int64, error) {
+	conf := newTailConfig(id, opts)
+	return c.tail(ctx, conf, func(chunk Chunk) (int64, error) {
+		if err := handle(chunk); err != nil {
+			return chunk.Offset, err
+		}
+		return chunk.Offset + int64(len(chunk.Data)), nil
+	})
+}
+
+// TailTo writes the output of a job to w, by default from the beginning
+// until the job has ended and all of its output has been written. Cancel
+// ctx to stop early.
+//
+// The offset just past the last byte written is always returned,
+// even with an error, so that WithOffset can be used to carry on from
+// the same place if the connection to the server was lost.
+func (c *Client) TailTo(ctx context.Context, w io.Writer, id string, opts ...TailOption) (int64, error) {
+	conf := newTailConfig(id, opts)
+	return c.tail(ctx, conf, func(chunk Chunk) (int64, error) {
+		out := w
+		if conf.stderr != nil && chunk.Stream == StreamStderr {
+			out = conf.stderr
+		}
+
+		n, err := out.Write(chunk.Data)
+		offset := chunk.Offset + int64(n)
+		if err != nil {
+			return offset, fmt.Errorf("unable to write output: %w", err)
+		}
+		return offset, nil
+	})
+}
+
+// newTailConfig builds the config for tailing the job with the given ID.
+func newTailConfig(id string, opts []TailOption) tailConfig {
+	conf := tailConfig{req: &pb.OutputJobRequest{Id: id}}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	return conf
+}
+
+// tail requests the output of a job, passing each chunk to handle.
+// handle returns the offset just past the output it handled, which tail
+// returns once the output ends or there's an error.
+func (c *Client) tail(ctx context.Context, conf tailConfig, handle func(Chunk) (int64, error)) (int64, error) {
+	offset := conf.req.GetStartOffset()
+	stream, err := c.service.Output(ctx, conf.req)
+	if err != nil {
+		return offset, convertError(err)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return offset, nil
+		}
+		if err != nil {
+			return offset, convertError(err)
+		}
+
+		if offset, err = handle(chunkFromPB(resp)); err != nil {
+			return offset, err
+		}
+	}
+}
@@ -0,0 +1,519 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	wgrpc "github.com/seanhagen/workernator/internal/grpc"
+	"github.com/seanhagen/workernator/internal/pb"
+	"github.com/seanhagen/workernator/internal/tlsconfig"
+	"github.com/seanhagen/workernator/library/api"
+)
+
+// the certificates in testdata have expired, so the tests pretend it's
+// still the time they were valid
+func testdataTime() time.Time {
+	return time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// startServer starts a server on a random local port, returning the
+// address it's listening on.
+func startServer(t *testing.T, opts ...grpc.ServerOption) string {
+	t.Helper()
+
+	creds, err := tlsconfig.ServerCredentials(tlsconfig.Config{
+		RootCert: "testdata/ca.pem",
+		Cert:     "testdata/server.pem",
+		Key:      "testdata/cakey.key",
+	})
+	require.NoError(t, err)
+
+	manager, err := api.NewManager(api.WithWorkDir(t.TempDir()))
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer(append(opts, grpc.Creds(creds))...)
+	pb.RegisterServiceServer(srv, wgrpc.NewServer(manager))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String()
+}
+
+func newTestClient(t *testing.T, addr string) *Client {
+	t.Helper()
+
+	c, err := New(addr,
+		WithRootCert("testdata/ca.pem"),
+		WithCert("testdata/client.pem"),
+		WithKey("testdata/cakey.key"),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func waitForJob(t *testing.T, c *Client, id string) *Job {
+	t.Helper()
+	var job *Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = c.Status(context.Background(), id)
+		require.NoError(t, err)
+		return job.Status != StatusRunning
+	}, 15*time.Second, 10*time.Millisecond)
+	return job
+}
+
+// waitForOutput waits until the output of a job is want.
+func waitForOutput(t *testing.T, c *Client, id, want string) {
+	t.Helper()
+	var out bytes.Buffer
+	require.Eventually(t, func() bool {
+		out.Reset()
+		_, err := c.TailTo(context.Background(), &out, id, WithFollow(false))
+		require.NoError(t, err)
+		return out.String() == want
+	}, 15*time.Second, 10*time.Millisecond, "output never became %q, last was %q", want, &out)
+}
+
+func TestNew_MissingCredentials(t *testing.T) {
+	tests := map[string][]Option{
+		"nothing":   nil,
+		"no root":   {WithCert("testdata/client.pem"), WithKey("testdata/cakey.key")},
+		"no cert":   {WithRootCert("testdata/ca.pem"), WithKey("testdata/cakey.key")},
+		"no key":    {WithRootCert("testdata/ca.pem"), WithCert("testdata/client.pem")},
+		"only root": {WithRootCert("testdata/ca.pem")},
+	}
+
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := New("127.0.0.1:0", opts...)
+			assert.True(t, errors.Is(err, ErrMissingCredentials), "expected ErrMissingCredentials, got %v", err)
+		})
+	}
+
+	_, err := New("127.0.0.1:0",
+		WithRootCert("testdata/ca.pem"),
+		WithCert("testdata/nope.pem"),
+		WithKey("testdata/cakey.key"),
+	)
+	assert.Error(t, err)
+}
+
+func TestClient_StartStatusTail(t *testing.T) {
+	c := newTestClient(t, startServer(t))
+	ctx := context.Background()
+
+	job, err := c.Start(ctx, "echo", []string{"hello", "world"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, job.ID)
+	assert.Equal(t, "echo", job.Command)
+	assert.Equal(t, []string{"hello", "world"}, job.Args)
+	assert.False(t, job.StartedAt.IsZero())
+
+	var buf bytes.Buffer
+	offset, err := c.TailTo(ctx, &buf, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world\n", buf.String())
+	assert.Equal(t, int64(buf.Len()), offset)
+
+	// carrying on from part of the way through
+	buf.Reset()
+	_, err = c.TailTo(ctx, &buf, job.ID, WithOffset(6))
+	require.NoError(t, err)
+	assert.Equal(t, "world\n", buf.String())
+
+	job = waitForJob(t, c, job.ID)
+	assert.Equal(t, StatusFinished, job.Status)
+	assert.False(t, job.EndedAt.IsZero())
+	assert.Zero(t, job.ExitCode)
+	assert.Equal(t, ReasonExited, job.Reason)
+	assert.Positive(t, job.Usage.PeakMemoryBytes)
+}
+
+func TestClient_Stop(t *testing.T) {
+	c := newTestClient(t, startServer(t))
+	ctx := context.Background()
+
+	job, err := c.Start(ctx, "sleep", []string{"30"})
+	require.NoError(t, err)
+
+	job, err = c.Stop(ctx, job.ID, WithGracePeriod(100*time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, StatusStopped, job.Status)
+	assert.Equal(t, "SIGKILL", job.Signal)
+	assert.Equal(t, -1, job.ExitCode)
+	assert.Equal(t, ReasonUserStopped, job.Reason)
+}
+
+func TestClient_StartMaxRuntime(t *testing.T) {
+	c := newTestClient(t, startServer(t))
+	ctx := context.Background()
+
+	script := `trap 'exit 0' TERM; while true; do sleep 0.1; done`
+	job, err := c.Start(ctx, "sh", []string{"-c", script}, WithMaxRuntime(200*time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, 200*time.Millisecond, job.MaxRuntime)
+
+	job = waitForJob(t, c, job.ID)
+	assert.Equal(t, StatusTimedOut, job.Status)
+	assert.Equal(t, ReasonTimeout, job.Reason)
+}
+
+func TestClient_List(t *testing.T) {
+	c := newTestClient(t, startServer(t))
+	ctx := context.Background()
+
+	sleeping, err := c.Start(ctx, "sleep", []string{"30"})
+	require.NoError(t, err)
+	finished, err := c.Start(ctx, "true", nil)
+	require.NoError(t, err)
+	waitForJob(t, c, finished.ID)
+
+	jobs, next, err := c.List(ctx, WithPageSize(1))
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, sleeping.ID, jobs[0].ID)
+	require.NotEmpty(t, next)
+
+	jobs, next, err = c.List(ctx, WithPageSize(1), WithPageToken(next))
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, finished.ID, jobs[0].ID)
+	assert.Empty(t, next)
+
+	jobs, _, err = c.List(ctx, WithStatuses(StatusRunning), WithCommand("sleep"), WithStartedAfter(sleeping.StartedAt))
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, sleeping.ID, jobs[0].ID)
+
+	jobs, _, err = c.List(ctx, WithStartedBefore(sleeping.StartedAt))
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	_, _, err = c.List(ctx, WithPageToken("nope"))
+	assert.True(t, errors.Is(err, ErrInvalidRequest), "expected ErrInvalidRequest, got %v", err)
+
+	_, err = c.Stop(ctx, sleeping.ID)
+	require.NoError(t, err)
+}
+
+func TestClient_Watch(t *testing.T) {
+	c := newTestClient(t, startServer(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ready := make(chan struct{})
+	events := make(chan Event, 10)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- c.Watch(ctx, func(ev Event) error {
+			events <- ev
+			return nil
+		}, WithReady(func() { close(ready) }))
+	}()
+	<-ready
+
+	job, err := c.Start(ctx, "true", nil)
+	require.NoError(t, err)
+
+	for _, expect := range []EventType{EventCreated, EventStarted, EventFinished} {
+		ev := <-events
+		assert.Equal(t, expect, ev.Type)
+		assert.Equal(t, job.ID, ev.Job.ID)
+		assert.False(t, ev.At.IsZero())
+	}
+
+	cancel()
+	err = <-errs
+	assert.True(t, errors.Is(err, context.Canceled), "expected context.Canceled, got %v", err)
+}
+
+func TestClient_WatchHandlerError(t *testing.T) {
+	c := newTestClient(t, startServer(t))
+	ctx := context.Background()
+
+	job, err := c.Start(ctx, "sleep", []string{"30"})
+	require.NoError(t, err)
+
+	errStop := errors.New("stop watching")
+	ready := make(chan struct{})
+	errs := make(chan error, 1)
+	go func() {
+		errs <- c.Watch(ctx, func(ev Event) error {
+			assert.Equal(t, EventStopped, ev.Type)
+			return errStop
+		}, WithWatchIDs(job.ID), WithReady(func() { close(ready) }))
+	}()
+	<-ready
+
+	_, err = c.Stop(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, errStop, <-errs)
+}
+
+func TestClient_Errors(t *testing.T) {
+	c := newTestClient(t, startServer(t))
+	ctx := context.Background()
+
+	_, err := c.Status(ctx, "nope")
+	assert.True(t, errors.Is(err, ErrJobNotFound), "expected ErrJobNotFound, got %v", err)
+
+	_, err = c.Stop(ctx, "nope")
+	assert.True(t, errors.Is(err, ErrJobNotFound), "expected ErrJobNotFound, got %v", err)
+
+	_, err = c.TailTo(ctx, &bytes.Buffer{}, "nope")
+	assert.True(t, errors.Is(err, ErrJobNotFound), "expected ErrJobNotFound, got %v", err)
+
+	_, err = c.Start(ctx, "not-a-real-command-workernator", nil)
+	assert.True(t, errors.Is(err, ErrInvalidRequest), "expected ErrInvalidRequest, got %v", err)
+
+	_, err = c.Start(ctx, "true", nil, WithLimits(Limits{MaxPids: -1}))
+	assert.True(t, errors.Is(err, ErrInvalidRequest), "expected ErrInvalidRequest, got %v", err)
+}
+
+func TestClient_Unauthenticated(t *testing.T) {
+	// the testdata client certificate doesn't say which user it's for
+	addr := startServer(t,
+		grpc.UnaryInterceptor(wgrpc.UnaryAuthInterceptor()),
+		grpc.StreamInterceptor(wgrpc.StreamAuthInterceptor()),
+	)
+	c := newTestClient(t, addr)
+
+	_, err := c.Status(context.Background(), "nope")
+	assert.True(t, errors.Is(err, ErrUnauthenticated), "expected ErrUnauthenticated, got %v", err)
+}
+
+func TestClient_Unavailable(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	c := newTestClient(t, addr)
+	_, err = c.Status(context.Background(), "nope")
+	assert.True(t, errors.Is(err, ErrUnavailable), "expected ErrUnavailable, got %v", err)
+}
+
+func TestClient_TailOptions(t *testing.T) {
+	c := newTestClient(t, startServer(t))
+	ctx := context.Background()
+
+	job, err := c.Start(ctx, "sh", []string{"-c", `printf 'one\ntwo\nthree\n'; sleep 30`})
+	require.NoError(t, err)
+	defer func() { _, _ = c.Stop(ctx, job.ID) }()
+
+	var buf bytes.Buffer
+	require.Eventually(t, func() bool {
+		buf.Reset()
+		_, err := c.TailTo(ctx, &buf, job.ID, WithFollow(false))
+		require.NoError(t, err)
+		return buf.String() == "one\ntwo\nthree\n"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	buf.Reset()
+	offset, err := c.TailTo(ctx, &buf, job.ID, WithLastLines(2), WithFollow(false))
+	require.NoError(t, err)
+	assert.Equal(t, "two\nthree\n", buf.String())
+	assert.Equal(t, int64(14), offset)
+
+	_, err = c.TailTo(ctx, &buf, job.ID, WithLastLines(2), WithOffset(1))
+	assert.True(t, errors.Is(err, ErrInvalidRequest), "expected ErrInvalidRequest, got %v", err)
+}
+
+func TestClient_TailStreams(t *testing.T) {
+	c := newTestClient(t, startServer(t))
+	ctx := context.Background()
+
+	job, err := c.Start(ctx, "sh", []string{"-c", `echo out; echo err >&2`})
+	require.NoError(t, err)
+
+	var stdout, stderr bytes.Buffer
+	_, err = c.TailTo(ctx, &stdout, job.ID, WithStderrTo(&stderr))
+	require.NoError(t, err)
+	assert.Equal(t, "out\n", stdout.String())
+	assert.Equal(t, "err\n", stderr.String())
+
+	stdout.Reset()
+	_, err = c.TailTo(ctx, &stdout, job.ID, WithStream(StreamStderr))
+	require.NoError(t, err)
+	assert.Equal(t, "err\n", stdout.String())
+}
+
+func TestClient_Tail(t *testing.T) {
+	c := newTestClient(t, startServer(t))
+	ctx := context.Background()
+
+	before := time.Now().Truncate(0)
+	job, err := c.Start(ctx, "sh", []string{"-c", `echo out; echo err >&2`})
+	require.NoError(t, err)
+
+	var chunks []Chunk
+	handle := func(chunk Chunk) error {
+		chunks = append(chunks, chunk)
+		return nil
+	}
+
+	// the streams are copied separately, so the stderr line could be
+	// before or after the stdout one
+	offset, err := c.Tail(ctx, job.ID, handle, WithStream(StreamStderr))
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	errOffset := chunks[0].Offset
+	assert.Equal(t, errOffset+4, offset)
+	assert.Equal(t, StreamStderr, chunks[0].Stream)
+	assert.Equal(t, "err\n", string(chunks[0].Data))
+	assert.True(t, chunks[0].At.IsZero())
+
+	chunks = nil
+	_, err = c.Tail(ctx, job.ID, handle, WithTimestamps(true))
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	for _, chunk := range chunks {
+		assert.False(t, chunk.At.Before(before), "output captured before the job started")
+	}
+
+	// errors from the handler stop the output, and the offset is where
+	// the failed chunk started
+	errStop := errors.New("stop")
+	offset, err = c.Tail(ctx, job.ID, func(chunk Chunk) error {
+		if chunk.Stream == StreamStderr {
+			return errStop
+		}
+		return nil
+	})
+	assert.True(t, errors.Is(err, errStop), "expected errStop, got %v", err)
+	assert.Equal(t, errOffset, offset)
+}
+
+func TestClient_SendInput(t *testing.T) {
+	c := newTestClient(t, startServer(t))
+	ctx := context.Background()
+
+	job, err := c.Start(ctx, "cat", nil, WithStdin())
+	require.NoError(t, err)
+
+	// input bigger than a single message
+	input := bytes.Repeat([]byte("0123456789abcdef"), inputChunkSize/8)
+	n, err := c.SendInput(ctx, job.ID, bytes.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(input)), n)
+
+	var out bytes.Buffer
+	_, err = c.TailTo(ctx, &out, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, input, out.Bytes())
+
+	_, err = c.SendInput(ctx, job.ID, strings.NewReader("more"))
+	assert.True(t, errors.Is(err, ErrNoInput), "expected ErrNoInput, got %v", err)
+}
+
+func TestClient_SendInputReadError(t *testing.T) {
+	c := newTestClient(t, startServer(t))
+	ctx := context.Background()
+
+	job, err := c.Start(ctx, "cat", nil, WithStdin())
+	require.NoError(t, err)
+
+	// failing to read the input leaves stdin open, so the rest can be
+	// sent later
+	errRead := errors.New("read failed")
+	n, err := c.SendInput(ctx, job.ID, io.MultiReader(strings.NewReader("one "), iotest.ErrReader(errRead)))
+	assert.True(t, errors.Is(err, errRead), "expected errRead, got %v", err)
+	assert.Equal(t, int64(4), n)
+
+	_, err = c.SendInput(ctx, job.ID, strings.NewReader("two\n"))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	_, err = c.TailTo(ctx, &out, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "one two\n", out.String())
+}
+
+func TestClient_Attach(t *testing.T) {
+	c := newTestClient(t, startServer(t))
+	ctx := context.Background()
+
+	job, err := c.Start(ctx, "sh", []string{"-c", `read a; stty size; echo "got $a"`}, WithTTY())
+	require.NoError(t, err)
+	assert.True(t, job.TTY)
+
+	// the size is sent before any input, as sending on an unbuffered
+	// channel only finishes once the size has been received
+	in, inW := io.Pipe()
+	sizes := make(chan WindowSize)
+	go func() {
+		sizes <- WindowSize{Rows: 30, Cols: 100}
+		_, _ = inW.Write([]byte("hello\n"))
+	}()
+
+	// Attach returns once the job has ended
+	var out bytes.Buffer
+	require.NoError(t, c.Attach(ctx, job.ID, in, &out, WithWindowSizes(sizes)))
+	assert.Equal(t, "hello\r\n30 100\r\ngot hello\r\n", out.String())
+	assert.Equal(t, StatusFinished, waitForJob(t, c, job.ID).Status)
+}
+
+func TestClient_AttachDetach(t *testing.T) {
+	c := newTestClient(t, startServer(t))
+	ctx := context.Background()
+
+	job, err := c.Start(ctx, "cat", nil, WithTTY())
+	require.NoError(t, err)
+
+	// the end of the input detaches, leaving the job running
+	require.NoError(t, c.Attach(ctx, job.ID, strings.NewReader("one\n"), io.Discard))
+	// the terminal echoes the input, then cat writes it back
+	waitForOutput(t, c, job.ID, "one\r\none\r\n")
+	running, err := c.Status(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRunning, running.Status)
+
+	// failing to read the input detaches as well
+	errRead := errors.New("read failed")
+	err = c.Attach(ctx, job.ID, iotest.ErrReader(errRead), io.Discard)
+	assert.True(t, errors.Is(err, errRead), "expected errRead, got %v", err)
+
+	// cat exits once it's sent an end-of-file character
+	require.NoError(t, c.Attach(ctx, job.ID, strings.NewReader("two\n\x04"), io.Discard))
+	assert.Equal(t, StatusFinished, waitForJob(t, c, job.ID).Status)
+
+	var out bytes.Buffer
+	_, err = c.TailTo(ctx, &out, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "one\r\none\r\ntwo\r\ntwo\r\n", out.String())
+
+	noTTY, err := c.Start(ctx, "cat", nil, WithStdin())
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = c.Stop(ctx, noTTY.ID) })
+	err = c.Attach(ctx, noTTY.ID, strings.NewReader("hi\n"), io.Discard)
+	assert.True(t, errors.Is(err, ErrNoInput), "expected ErrNoInput, got %v", err)
+}
+
+func TestClient_TailCancel(t *testing.T) {
+	c := newTestClient(t, startServer(t))
+
+	job, err := c.Start(context.Background(), "sleep", []string{"30"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	_, err = c.TailTo(ctx, &bytes.Buffer{}, job.ID)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected context.DeadlineExceeded, got %v", err)
+}
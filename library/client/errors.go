@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrMissingCredentials is returned by New when the root certificate,
+// client certificate, or key haven't been provided.
+var ErrMissingCredentials = errors.New("missing credentials")
+
+// ErrJobNotFound is returned when the server doesn't know about a job
+// with the ID provided, or the user isn't allowed to see it.
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrInvalidRequest is returned when the server rejects a request as
+// invalid, such as when the command can't be run or the resource
+// limits are too high.
+var ErrInvalidRequest = errors.New("invalid request")
+
+// ErrPermissionDenied is returned when the user isn't allowed to make
+// the request.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// ErrUnauthenticated is returned when the server couldn't figure out
+// which user made the request.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// ErrUnavailable is returned when the server can't be reached, which
+// includes the server rejecting the client certificate.
+var ErrUnavailable = errors.New("server unavailable")
+
+// ErrTooSlow is returned by Watch when the server ended the stream of
+// events because they weren't being handled quickly enough.
+var ErrTooSlow = errors.New("too slow handling events")
+
+// ErrNoInput is returned by SendInput and Attach when the job isn't
+// accepting input, either because it wasn't started using WithStdin or
+// WithTTY, its stdin has been closed, or input is already being sent to
+// it. Attach also returns it for jobs that weren't started using WithTTY.
+var ErrNoInput = errors.New("job isn't accepting input")
+
+// codeErrors maps GRPC status codes to the error they're converted to.
+var codeErrors = map[codes.Code]error{
+	codes.NotFound:           ErrJobNotFound,
+	codes.InvalidArgument:    ErrInvalidRequest,
+	codes.PermissionDenied:   ErrPermissionDenied,
+	codes.Unauthenticated:    ErrUnauthenticated,
+	codes.Unavailable:        ErrUnavailable,
+	codes.Aborted:            ErrTooSlow,
+	codes.FailedPrecondition: ErrNoInput,
+	codes.Canceled:           context.Canceled,
+	codes.DeadlineExceeded:   context.DeadlineExceeded,
+}
+
+// convertError converts a GRPC status error into one wrapping the
+// matching error from this package, so callers can use errors.Is
+// without knowing anything about GRPC.
+func convertError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	if target, ok := codeErrors[st.Code()]; ok {
+		return fmt.Errorf("%w: %s", target, st.Message())
+	}
+	return fmt.Errorf("request failed with code %v: %s", st.Code(), st.Message())
+}
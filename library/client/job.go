@@ -0,0 +1,353 @@
+package client
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/seanhagen/workernator/internal/pb"
+)
+
+// JobStatus describes what state a job is currently in.
+type JobStatus int
+
+const (
+	// StatusUnknown means the server didn't say what state the job is
+	// in.
+	StatusUnknown JobStatus = iota
+	// StatusRunning means the job is still processing.
+	StatusRunning
+	// StatusFailed means the job either failed to start, or exited
+	// with a non-zero exit code.
+	StatusFailed
+	// StatusFinished means the job completed successfully.
+	StatusFinished
+	// StatusStopped means the job was stopped by a user before it
+	// finished.
+	StatusStopped
+	// StatusTimedOut means the job was stopped because it ran for longer
+	// than its max runtime.
+	StatusTimedOut
+)
+
+// String returns a human-readable version of the status.
+func (s JobStatus) String() string {
+	switch s {
+	case StatusRunning:
+		return "Running"
+	case StatusFailed:
+		return "Failed"
+	case StatusFinished:
+		return "Finished"
+	case StatusStopped:
+		return "Stopped"
+	case StatusTimedOut:
+		return "TimedOut"
+	}
+	return "Unknown"
+}
+
+// statusFromPB maps GRPC job statuses to their client equivalent.
+var statusFromPB = map[pb.JobStatus]JobStatus{
+	pb.JobStatus_Unknown:  StatusUnknown,
+	pb.JobStatus_Running:  StatusRunning,
+	pb.JobStatus_Failed:   StatusFailed,
+	pb.JobStatus_Finished: StatusFinished,
+	pb.JobStatus_Stopped:  StatusStopped,
+	pb.JobStatus_TimedOut: StatusTimedOut,
+}
+
+// statusToPB maps client job statuses to their GRPC equivalent.
+var statusToPB = map[JobStatus]pb.JobStatus{
+	StatusUnknown:  pb.JobStatus_Unknown,
+	StatusRunning:  pb.JobStatus_Running,
+	StatusFailed:   pb.JobStatus_Failed,
+	StatusFinished: pb.JobStatus_Finished,
+	StatusStopped:  pb.JobStatus_Stopped,
+	StatusTimedOut: pb.JobStatus_TimedOut,
+}
+
+// TerminationReason describes why a job ended.
+type TerminationReason int
+
+const (
+	// ReasonNone means the job hasn't ended yet.
+	ReasonNone TerminationReason = iota
+	// ReasonExited means the job exited on its own, whether or not it
+	// was successful.
+	ReasonExited
+	// ReasonSignaled means the job was killed by a signal that didn't
+	// come from the server.
+	ReasonSignaled
+	// ReasonUserStopped means the job was stopped by a user.
+	ReasonUserStopped
+	// ReasonOOMKilled means the job failed after a process in it was
+	// killed for using more memory than its limit allowed.
+	ReasonOOMKilled
+	// ReasonPidsExhausted means the job failed after it tried to start
+	// more processes than its limit allowed.
+	ReasonPidsExhausted
+	// ReasonTimeout means the job was stopped because it ran for longer
+	// than its max runtime.
+	ReasonTimeout
+)
+
+// String returns a human-readable version of the reason.
+func (r TerminationReason) String() string {
+	switch r {
+	case ReasonExited:
+		return "Exited"
+	case ReasonSignaled:
+		return "Signaled"
+	case ReasonUserStopped:
+		return "UserStopped"
+	case ReasonOOMKilled:
+		return "OOMKilled"
+	case ReasonPidsExhausted:
+		return "PidsExhausted"
+	case ReasonTimeout:
+		return "Timeout"
+	}
+	return "None"
+}
+
+// reasonFromPB maps GRPC termination reasons to their client equivalent.
+var reasonFromPB = map[pb.TerminationReason]TerminationReason{
+	pb.TerminationReason_NotTerminated: ReasonNone,
+	pb.TerminationReason_Exited:        ReasonExited,
+	pb.TerminationReason_Signaled:      ReasonSignaled,
+	pb.TerminationReason_UserStopped:   ReasonUserStopped,
+	pb.TerminationReason_OOMKilled:     ReasonOOMKilled,
+	pb.TerminationReason_PidsExhausted: ReasonPidsExhausted,
+	pb.TerminationReason_Timeout:       ReasonTimeout,
+}
+
+// EventType describes what happened to a job.
+type EventType int
+
+const (
+	// EventUnknown means the server didn't say what happened.
+	EventUnknown EventType = iota
+	// EventCreated is sent once a job has been added, just before
+	// EventStarted. The job is as it was just before its command was
+	// started, so has StatusUnknown. Jobs that couldn't be started are
+	// never added, so have no events sent.
+	EventCreated
+	// EventStarted is sent once the job command is running.
+	EventStarted
+	// EventStopped is sent when a job ends after being stopped by a
+	// user, or by the server shutting down.
+	EventStopped
+	// EventTimedOut is sent when a job ends after running for longer
+	// than its max runtime.
+	EventTimedOut
+	// EventFinished is sent when a job ends successfully.
+	EventFinished
+	// EventFailed is sent when a job ends unsuccessfully.
+	EventFailed
+)
+
+// String returns a human-readable version of the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventCreated:
+		return "Created"
+	case EventStarted:
+		return "Started"
+	case EventStopped:
+		return "Stopped"
+	case EventTimedOut:
+		return "TimedOut"
+	case EventFinished:
+		return "Finished"
+	case EventFailed:
+		return "Failed"
+	}
+	return "Unknown"
+}
+
+// eventTypeFromPB maps GRPC event types to their client equivalent.
+var eventTypeFromPB = map[pb.JobEventType]EventType{
+	pb.JobEventType_UnknownEvent: EventUnknown,
+	pb.JobEventType_JobCreated:   EventCreated,
+	pb.JobEventType_JobStarted:   EventStarted,
+	pb.JobEventType_JobStopped:   EventStopped,
+	pb.JobEventType_JobTimedOut:  EventTimedOut,
+	pb.JobEventType_JobFinished:  EventFinished,
+	pb.JobEventType_JobFailed:    EventFailed,
+}
+
+// Event is something that happened to a job.
+type Event struct {
+	Type EventType
+	// Job is a snapshot of the job taken just after the event happened.
+	Job *Job
+	At  time.Time
+}
+
+// eventFromPB converts the GRPC JobEvent message into an Event.
+func eventFromPB(ev *pb.JobEvent) Event {
+	return Event{
+		Type: eventTypeFromPB[ev.GetType()],
+		Job:  jobFromPB(ev.GetJob()),
+		At:   ev.GetTime().AsTime(),
+	}
+}
+
+// Job is the state of a job at the time it was requested.
+type Job struct {
+	ID string
+	// Owner is the user that started the job.
+	Owner    string
+	Status   JobStatus
+	Command  string
+	Args     []string
+	ErrorMsg string
+	// Limits are the resource limits the job is running with. These
+	// are zero if the server isn't applying limits.
+	Limits Limits
+	// MaxRuntime is how long the job is allowed to run, or zero if it
+	// can run forever.
+	MaxRuntime time.Duration
+	// TTY is set if the job was started using WithTTY, so it can be
+	// attached to.
+	TTY bool
+	// Signal is the name of the signal that ended the job, ie
+	// 'SIGKILL'. It's empty if the job is running or exited on its own.
+	Signal string
+	// Reason is why the job ended.
+	Reason TerminationReason
+	// ExitCode is the exit code of the job once it has ended, or -1 if
+	// it was ended by a signal.
+	ExitCode int
+	// Usage is the resources the job used, which is only set once the
+	// job has ended.
+	Usage     ResourceUsage
+	StartedAt time.Time
+	// EndedAt is zero while the job is still running.
+	EndedAt time.Time
+}
+
+// Limits are the resource limits for a job. When starting a job, any
+// field left as zero uses the default configured on the server.
+type Limits struct {
+	// MaxPids is the maximum number of processes the job can have.
+	MaxPids int64
+	// CPUQuota is how much CPU time the job can use every CPUPeriod.
+	CPUQuota time.Duration
+	// CPUPeriod is the period CPUQuota applies to.
+	CPUPeriod time.Duration
+	// MemoryBytes is the maximum amount of memory the job can use.
+	MemoryBytes int64
+	// IO limits the bytes per second the job can read from or write to
+	// block devices.
+	IO []IOLimit
+}
+
+// IOLimit limits the bytes per second a job can read from or write to a
+// block device. Zero means unlimited.
+type IOLimit struct {
+	// Device is the block device, in "major:minor" form.
+	Device   string
+	ReadBPS  int64
+	WriteBPS int64
+}
+
+// ResourceUsage is how much of the system's resources a job used.
+type ResourceUsage struct {
+	// UserCPU is the CPU time spent running the job's code.
+	UserCPU time.Duration
+	// SystemCPU is the CPU time the kernel spent on behalf of the job.
+	SystemCPU time.Duration
+	// PeakMemoryBytes is the most memory the job used at once.
+	PeakMemoryBytes int64
+	// IOReadBytes and IOWriteBytes are how many bytes the job read from
+	// and wrote to block devices.
+	IOReadBytes  int64
+	IOWriteBytes int64
+}
+
+// jobFromPB converts the GRPC Job message into a Job.
+func jobFromPB(job *pb.Job) *Job {
+	out := &Job{
+		ID:         job.GetId(),
+		Owner:      job.GetOwner(),
+		Status:     statusFromPB[job.GetStatus()],
+		Command:    job.GetCommand(),
+		Args:       job.GetArgs(),
+		ErrorMsg:   job.GetErrorMsg(),
+		Limits:     limitsFromPB(job.GetLimits()),
+		MaxRuntime: job.GetMaxRuntime().AsDuration(),
+		TTY:        job.GetTty(),
+		Signal:     job.GetSignal(),
+		Reason:     reasonFromPB[job.GetTerminationReason()],
+		ExitCode:   int(job.GetExitCode()),
+		Usage:      usageFromPB(job.GetUsage()),
+	}
+
+	if job.GetStartedAt() != nil {
+		out.StartedAt = job.GetStartedAt().AsTime()
+	}
+	if job.GetEndedAt() != nil {
+		out.EndedAt = job.GetEndedAt().AsTime()
+	}
+
+	return out
+}
+
+// usageFromPB converts the GRPC ResourceUsage message into
+// ResourceUsage.
+func usageFromPB(usage *pb.ResourceUsage) ResourceUsage {
+	return ResourceUsage{
+		UserCPU:         usage.GetUserCpu().AsDuration(),
+		SystemCPU:       usage.GetSystemCpu().AsDuration(),
+		PeakMemoryBytes: usage.GetPeakMemoryBytes(),
+		IOReadBytes:     usage.GetIoReadBytes(),
+		IOWriteBytes:    usage.GetIoWriteBytes(),
+	}
+}
+
+// limitsFromPB converts the GRPC ResourceLimits message into Limits.
+func limitsFromPB(limits *pb.ResourceLimits) Limits {
+	out := Limits{
+		MaxPids:     limits.GetMaxPids(),
+		CPUQuota:    limits.GetCpuQuota().AsDuration(),
+		CPUPeriod:   limits.GetCpuPeriod().AsDuration(),
+		MemoryBytes: limits.GetMemoryBytes(),
+	}
+
+	for _, io := range limits.GetIo() {
+		out.IO = append(out.IO, IOLimit{
+			Device:   io.GetDevice(),
+			ReadBPS:  io.GetReadBytesPerSec(),
+			WriteBPS: io.GetWriteBytesPerSec(),
+		})
+	}
+
+	return out
+}
+
+// limitsToPB converts Limits into the GRPC ResourceLimits message.
+func limitsToPB(limits Limits) *pb.ResourceLimits {
+	out := &pb.ResourceLimits{
+		MaxPids:     limits.MaxPids,
+		MemoryBytes: limits.MemoryBytes,
+	}
+
+	if limits.CPUQuota != 0 {
+		out.CpuQuota = durationpb.New(limits.CPUQuota)
+	}
+	if limits.CPUPeriod != 0 {
+		out.CpuPeriod = durationpb.New(limits.CPUPeriod)
+	}
+
+	for _, io := range limits.IO {
+		out.Io = append(out.Io, &pb.IOLimit{
+			Device:           io.Device,
+			ReadBytesPerSec:  io.ReadBPS,
+			WriteBytesPerSec: io.WriteBPS,
+		})
+	}
+
+	return out
+}
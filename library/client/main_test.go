@@ -0,0 +1,17 @@
+package client
+
+import (
+	"os"
+	"testing"
+
+	"github.com/seanhagen/workernator/internal/tlsconfig"
+	"github.com/seanhagen/workernator/library/api"
+)
+
+func TestMain(m *testing.M) {
+	// the test server starts jobs by re-executing the test binary
+	api.Init()
+	// the certificates in testdata have expired
+	tlsconfig.SetTime(testdataTime)
+	os.Exit(m.Run())
+}
@@ -96,12 +96,18 @@ func generateCertificate(certFor string, isClient bool) error {
 		config,
 	)
 
+	// the client config reads the user the certificate is for from the
+	// environment, as both openssl commands below load it
+	var env map[string]string
+	if isClient {
+		env = map[string]string{"WORKERNATOR_USER": certFor}
+	}
+
 	keyPath := buildDir + "/ca.key"
 	csrPath := buildDir + "/" + certFor + ".csr"
 	stdOut := bytes.NewBuffer(nil)
 	stdErr := bytes.NewBuffer(nil)
-	_, err := sh.Exec(nil, stdOut, stdErr, "openssl", "req", "-new", "-key", keyPath,
-		"-out", csrPath, "-config", config)
+	_, err := sh.Exec(env, stdOut, stdErr, "openssl", "req", "-new", "-key", keyPath, "-out", csrPath, "-config", config)
 	if err != nil {
 		fmt.Fprintf(os.Stdout, " ERROR: %v\n", err)
 		fmt.Fprintf(os.Stdout, stdOut.String())
@@ -115,7 +121,7 @@ func generateCertificate(certFor string, isClient bool) error {
 	pemPath := buildDir + "/" + certFor + ".pem"
 	stdOut.Truncate(0)
 	stdErr.Truncate(0)
-	_, err = sh.Exec(nil, io.Discard, stdErr, "openssl", "x509", "-req", "-in", csrPath, "-CA", caPemPath,
+	_, err = sh.Exec(env, io.Discard, stdErr, "openssl", "x509", "-req", "-in", csrPath, "-CA", caPemPath,
 		"-CAkey", caKeyPath, "-CAcreateserial", "-out", pemPath, "-days", "90",
 		"-extfile", config, "-extensions", "req_ext")
 	if err != nil {